@@ -1,56 +1,476 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-var client *http.Client
+// DefaultPriceEndpoint is Binance's single symbol price path, with %s for the
+// symbol. Proxies routing it elsewhere can replace it with SetPriceEndpoint.
+const DefaultPriceEndpoint = "/api/v3/ticker/price?symbol=%s"
 
-var apiURL = "https://api.binance.com"
-var UpdateInterval = 1 * time.Second
-var PricePrecision = 3
+// restSettings is what REST requests are built from. It's never modified in
+// place: setters swap in a changed copy, so requests running on other
+// goroutines always see one consistent set.
+type restSettings struct {
+	client        *http.Client
+	apiURL        string
+	priceEndpoint string
+}
+
+var rest atomic.Pointer[restSettings]
+
+// Serializes the setters' copy and swap of rest
+var restMu sync.Mutex
+
+// updateRest swaps in a copy of the REST settings changed by change
+func updateRest(change func(s *restSettings)) {
+	restMu.Lock()
+	defer restMu.Unlock()
+	s := *rest.Load()
+	change(&s)
+	rest.Store(&s)
+}
+
+// Set from the UI while polling goroutines read them, see the accessors below
+var (
+	updateInterval atomic.Int64 // time.Duration
+	pricePrecision atomic.Int64
+)
+
+// UpdateInterval is how often prices are fetched
+func UpdateInterval() time.Duration {
+	return time.Duration(updateInterval.Load())
+}
+
+func SetUpdateInterval(interval time.Duration) {
+	updateInterval.Store(int64(interval))
+}
+
+// PricePrecision is the number of decimals shown for prices whose exchange
+// tick size isn't known
+func PricePrecision() int {
+	return int(pricePrecision.Load())
+}
+
+func SetPricePrecision(precision int) {
+	pricePrecision.Store(int64(precision))
+}
+
+// Retry settings for GetPrice: attempts are spaced RetryBaseDelay, 2x, 4x, ...
+// and retrying stops once MaxRetryDuration would be exceeded
+var MaxRetries = 3
+var RetryBaseDelay = 100 * time.Millisecond
+var MaxRetryDuration = 3 * time.Second
 
 type Response struct {
 	Symbol string `json:"symbol"`
 	Price  string `json:"price"`
 }
 
+// binanceError is the body of a Binance API error response
+type binanceError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+// Binance error code for an unknown symbol
+const binanceInvalidSymbol = -1121
+
+// apiError builds the error for a non-200 response, wrapping ErrRateLimited or
+// ErrInvalidSymbol when the response is one of those. prefix starts the
+// message, e.g. "API error [BTCUSDT]".
+func apiError(prefix string, resp *http.Response, body []byte) error {
+	var apiErr binanceError
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot:
+		return fmt.Errorf("%s: %w: %s - %s", prefix, ErrRateLimited, resp.Status, string(body))
+	case json.Unmarshal(body, &apiErr) == nil && apiErr.Code == binanceInvalidSymbol:
+		return fmt.Errorf("%s: %w: %s", prefix, ErrInvalidSymbol, apiErr.Msg)
+	}
+	return fmt.Errorf("%s: %s - %s", prefix, resp.Status, string(body))
+}
+
+type Ticker24h struct {
+	PriceChangePercent float64
+	HighPrice          float64
+	LowPrice           float64
+	Volume             float64
+}
+
+type ticker24hResponse struct {
+	Symbol             string `json:"symbol"`
+	PriceChangePercent string `json:"priceChangePercent"`
+	HighPrice          string `json:"highPrice"`
+	LowPrice           string `json:"lowPrice"`
+	Volume             string `json:"volume"`
+}
+
+type bookTickerResponse struct {
+	Symbol   string `json:"symbol"`
+	BidPrice string `json:"bidPrice"`
+	AskPrice string `json:"askPrice"`
+}
+
+type Kline struct {
+	OpenTime time.Time
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+	Volume   float64
+}
+
 func init() {
-	client = &http.Client{
-		Timeout: 1 * time.Second,
+	rest.Store(&restSettings{
+		client:        &http.Client{Timeout: 1 * time.Second},
+		apiURL:        "https://api.binance.com",
+		priceEndpoint: DefaultPriceEndpoint,
+	})
+	SetUpdateInterval(1 * time.Second)
+	SetPricePrecision(3)
+}
+
+// SetHTTPTimeout replaces the HTTP client with one using timeout
+func SetHTTPTimeout(timeout time.Duration) {
+	updateRest(func(s *restSettings) {
+		s.client = &http.Client{Timeout: timeout}
+	})
+}
+
+// SetAPIURL points all REST requests at baseURL, e.g. a testnet or Binance.US
+func SetAPIURL(baseURL string) {
+	updateRest(func(s *restSettings) {
+		s.apiURL = strings.TrimRight(baseURL, "/")
+	})
+}
+
+// ValidPriceEndpoint reports whether template is a path with exactly one %s,
+// for the symbol, and no other formatting verbs
+func ValidPriceEndpoint(template string) bool {
+	return strings.HasPrefix(template, "/") && strings.Count(template, "%s") == 1 && strings.Count(template, "%") == 1
+}
+
+// SetPriceEndpoint makes GetPrice request template under the API URL, with
+// the symbol in place of its %s. Invalid templates are ignored.
+func SetPriceEndpoint(template string) {
+	if ValidPriceEndpoint(template) {
+		updateRest(func(s *restSettings) {
+			s.priceEndpoint = template
+		})
 	}
 }
 
 func GetPrice(symbol string) (string, error) {
-	resp, err := client.Get(fmt.Sprintf("%s/api/v3/ticker/price?symbol=%s", apiURL, symbol))
+	return GetPriceContext(context.Background(), symbol)
+}
+
+// GetPriceContext is GetPrice with a context that cancels the in-flight request
+// and any pending retry
+func GetPriceContext(ctx context.Context, symbol string) (string, error) {
+	start := time.Now()
+	delay := RetryBaseDelay
+
+	var lastErr error
+	for attempt := 1; attempt <= MaxRetries; attempt++ {
+		price, retryable, err := fetchPrice(ctx, symbol)
+		if err == nil {
+			return price, nil
+		}
+		lastErr = err
+
+		if !retryable || attempt == MaxRetries || time.Since(start)+delay > MaxRetryDuration {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	return "", lastErr
+}
+
+// fetchPrice performs a single price request. retryable reports whether the
+// failure is transient (network error or HTTP 5xx). Rate limiting isn't: the
+// limiter stays paused until the server's Retry-After, so a retry would only
+// fail again.
+func fetchPrice(ctx context.Context, symbol string) (price string, retryable bool, err error) {
+	settings := rest.Load()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, settings.apiURL+fmt.Sprintf(settings.priceEndpoint, url.QueryEscape(symbol)), nil)
 	if err != nil {
-		return "", fmt.Errorf("HTTP request failed [%s]: %w", symbol, err)
+		return "", false, fmt.Errorf("request build failed [%s]: %w", symbol, err)
+	}
+
+	resp, err := doRequestWith(settings.client, req)
+	if err != nil {
+		// A cancelled context or a paused limiter is final, don't retry it
+		return "", ctx.Err() == nil && !errors.Is(err, ErrRateLimited), fmt.Errorf("HTTP request failed [%s]: %w", symbol, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API error [%s]: %s - %s", symbol, resp.Status, string(bodyBytes))
+		retryable := resp.StatusCode >= 500
+		return "", retryable, apiError(fmt.Sprintf("API error [%s]", symbol), resp, bodyBytes)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("body read error [%s]: %w", symbol, err)
+		return "", true, fmt.Errorf("body read error [%s]: %w", symbol, err)
 	}
 
 	var priceResp Response
 	if err := json.Unmarshal(body, &priceResp); err != nil {
-		return "", fmt.Errorf("JSON parse error [%s]: %w, Received Data: %s", symbol, err, string(body))
+		return "", false, fmt.Errorf("JSON parse error [%s]: %w, Received Data: %s", symbol, err, string(body))
 	}
 
 	if _, err := strconv.ParseFloat(priceResp.Price, 64); err != nil {
-		return "", fmt.Errorf("invalid price format [%s]: %w, Received Price: %s", symbol, err, priceResp.Price)
+		return "", false, fmt.Errorf("invalid price format [%s]: %w, Received Price: %s", symbol, err, priceResp.Price)
+	}
+
+	return priceResp.Price, false, nil
+}
+
+func GetPrices(symbols []string) (map[string]string, error) {
+	return GetPricesContext(context.Background(), symbols)
+}
+
+// GetPricesContext is GetPrices with a context that cancels the request
+func GetPricesContext(ctx context.Context, symbols []string) (map[string]string, error) {
+	settings := rest.Load()
+	// A custom price endpoint has no known batch form, so each symbol is
+	// requested from it on its own
+	if settings.priceEndpoint != DefaultPriceEndpoint {
+		return getPricesEach(ctx, symbols)
+	}
+
+	symbolsJSON, err := json.Marshal(symbols)
+	if err != nil {
+		return nil, fmt.Errorf("symbol list encode error: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/v3/ticker/price?symbols=%s", settings.apiURL, url.QueryEscape(string(symbolsJSON))), nil)
+	if err != nil {
+		return nil, fmt.Errorf("batch request build failed: %w", err)
+	}
+
+	resp, err := doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP batch request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, apiError("API batch error", resp, bodyBytes)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("batch body read error: %w", err)
+	}
+
+	var priceResps []Response
+	if err := json.Unmarshal(body, &priceResps); err != nil {
+		return nil, fmt.Errorf("batch JSON parse error: %w, Received Data: %s", err, string(body))
+	}
+
+	prices := make(map[string]string, len(priceResps))
+	for _, priceResp := range priceResps {
+		if _, err := strconv.ParseFloat(priceResp.Price, 64); err != nil {
+			return nil, fmt.Errorf("invalid price format [%s]: %w, Received Price: %s", priceResp.Symbol, err, priceResp.Price)
+		}
+		prices[priceResp.Symbol] = priceResp.Price
+	}
+
+	return prices, nil
+}
+
+// getPricesEach fetches symbols one by one through GetPriceContext. It
+// returns the prices it got along with the errors of the symbols it didn't,
+// and stops early on cancellation or rate limiting.
+func getPricesEach(ctx context.Context, symbols []string) (map[string]string, error) {
+	prices := make(map[string]string, len(symbols))
+	var errs []error
+	for _, symbol := range symbols {
+		price, err := GetPriceContext(ctx, symbol)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, ErrRateLimited) {
+				return nil, err
+			}
+			errs = append(errs, err)
+			continue
+		}
+		prices[symbol] = price
+	}
+	return prices, errors.Join(errs...)
+}
+
+// GetKlines returns symbol's last limit candles of interval. ctx cancels the
+// request.
+func GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/v3/klines?symbol=%s&interval=%s&limit=%d", rest.Load().apiURL, url.QueryEscape(symbol), url.QueryEscape(interval), limit), nil)
+	if err != nil {
+		return nil, fmt.Errorf("klines request build failed [%s]: %w", symbol, err)
+	}
+
+	resp, err := doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP klines request failed [%s]: %w", symbol, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, apiError(fmt.Sprintf("API klines error [%s]", symbol), resp, bodyBytes)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("klines body read error [%s]: %w", symbol, err)
+	}
+
+	// Each kline is an array: [openTime, open, high, low, close, volume, closeTime, ...]
+	var rows [][]json.RawMessage
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return nil, fmt.Errorf("klines JSON parse error [%s]: %w, Received Data: %s", symbol, err, string(body))
+	}
+
+	klines := make([]Kline, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 6 {
+			return nil, fmt.Errorf("short kline row [%s]: %d fields", symbol, len(row))
+		}
+
+		var openTime int64
+		if err := json.Unmarshal(row[0], &openTime); err != nil {
+			return nil, fmt.Errorf("invalid kline open time [%s]: %w", symbol, err)
+		}
+
+		values := make([]float64, 5)
+		for i := range values {
+			var str string
+			if err := json.Unmarshal(row[i+1], &str); err != nil {
+				return nil, fmt.Errorf("invalid kline field [%s]: %w", symbol, err)
+			}
+			v, err := strconv.ParseFloat(str, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid kline value [%s]: %w, Received Value: %s", symbol, err, str)
+			}
+			values[i] = v
+		}
+
+		klines = append(klines, Kline{
+			OpenTime: time.UnixMilli(openTime),
+			Open:     values[0],
+			High:     values[1],
+			Low:      values[2],
+			Close:    values[3],
+			Volume:   values[4],
+		})
+	}
+
+	return klines, nil
+}
+
+// GetTicker24h returns symbol's rolling 24h statistics. ctx cancels the
+// request.
+func GetTicker24h(ctx context.Context, symbol string) (Ticker24h, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/v3/ticker/24hr?symbol=%s", rest.Load().apiURL, url.QueryEscape(symbol)), nil)
+	if err != nil {
+		return Ticker24h{}, fmt.Errorf("24h ticker request build failed [%s]: %w", symbol, err)
+	}
+
+	resp, err := doRequest(req)
+	if err != nil {
+		return Ticker24h{}, fmt.Errorf("HTTP 24h ticker request failed [%s]: %w", symbol, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return Ticker24h{}, apiError(fmt.Sprintf("API 24h ticker error [%s]", symbol), resp, bodyBytes)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Ticker24h{}, fmt.Errorf("24h ticker body read error [%s]: %w", symbol, err)
+	}
+
+	var tickerResp ticker24hResponse
+	if err := json.Unmarshal(body, &tickerResp); err != nil {
+		return Ticker24h{}, fmt.Errorf("24h ticker JSON parse error [%s]: %w, Received Data: %s", symbol, err, string(body))
+	}
+
+	var ticker Ticker24h
+	fields := []struct {
+		name  string
+		value string
+		dest  *float64
+	}{
+		{"priceChangePercent", tickerResp.PriceChangePercent, &ticker.PriceChangePercent},
+		{"highPrice", tickerResp.HighPrice, &ticker.HighPrice},
+		{"lowPrice", tickerResp.LowPrice, &ticker.LowPrice},
+		{"volume", tickerResp.Volume, &ticker.Volume},
+	}
+	for _, field := range fields {
+		v, err := strconv.ParseFloat(field.value, 64)
+		if err != nil {
+			return Ticker24h{}, fmt.Errorf("invalid %s format [%s]: %w, Received Value: %s", field.name, symbol, err, field.value)
+		}
+		*field.dest = v
+	}
+
+	return ticker, nil
+}
+
+// GetBookTicker returns the best bid and ask prices of symbol's order book.
+// ctx cancels the request.
+func GetBookTicker(ctx context.Context, symbol string) (bid, ask string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/v3/ticker/bookTicker?symbol=%s", rest.Load().apiURL, url.QueryEscape(symbol)), nil)
+	if err != nil {
+		return "", "", fmt.Errorf("book ticker request build failed [%s]: %w", symbol, err)
+	}
+
+	resp, err := doRequest(req)
+	if err != nil {
+		return "", "", fmt.Errorf("HTTP book ticker request failed [%s]: %w", symbol, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", "", apiError(fmt.Sprintf("API book ticker error [%s]", symbol), resp, bodyBytes)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("book ticker body read error [%s]: %w", symbol, err)
+	}
+
+	var bookResp bookTickerResponse
+	if err := json.Unmarshal(body, &bookResp); err != nil {
+		return "", "", fmt.Errorf("book ticker JSON parse error [%s]: %w, Received Data: %s", symbol, err, string(body))
+	}
+
+	for _, price := range []string{bookResp.BidPrice, bookResp.AskPrice} {
+		if _, err := strconv.ParseFloat(price, 64); err != nil {
+			return "", "", fmt.Errorf("invalid book price format [%s]: %w, Received Price: %s", symbol, err, price)
+		}
 	}
 
-	return priceResp.Price, nil
+	return bookResp.BidPrice, bookResp.AskPrice, nil
 }
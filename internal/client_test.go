@@ -0,0 +1,259 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useTestServer points the REST client at a server running handler until
+// the test ends
+func useTestServer(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	oldURL, oldDelay := rest.Load().apiURL, RetryBaseDelay
+	SetAPIURL(server.URL)
+	RetryBaseDelay = time.Millisecond
+	t.Cleanup(func() {
+		SetAPIURL(oldURL)
+		RetryBaseDelay = oldDelay
+	})
+}
+
+func TestGetPrice(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr string
+	}{
+		{"valid price", http.StatusOK, `{"symbol":"BTCUSDT","price":"67000.12000000"}`, "67000.12000000", ""},
+		{"non-200 status", http.StatusInternalServerError, `{"code":-1000,"msg":"internal"}`, "", "500 Internal Server Error"},
+		{"malformed JSON", http.StatusOK, `{"symbol":"BTCUSDT","price":`, "", "JSON parse error"},
+		{"non-numeric price", http.StatusOK, `{"symbol":"BTCUSDT","price":"abc"}`, "", "invalid price format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v3/ticker/price" || r.URL.Query().Get("symbol") != "BTCUSDT" {
+					t.Errorf("unexpected request %s", r.URL)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			price, err := GetPrice("BTCUSDT")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("GetPrice: %v", err)
+				}
+				if price != tt.want {
+					t.Errorf("price = %q, want %q", price, tt.want)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetPriceContextCancelled(t *testing.T) {
+	requests := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	// Long enough that a retry sleep would show in the elapsed time
+	RetryBaseDelay = time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	_, err := GetPriceContext(ctx, "BTCUSDT")
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("took %s, want an immediate return", elapsed)
+	}
+	if requests != 0 {
+		t.Errorf("server got %d requests, want 0", requests)
+	}
+}
+
+func TestGetPriceErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header http.Header
+		body   string
+		want   error
+	}{
+		{"invalid symbol", http.StatusBadRequest, nil, `{"code":-1121,"msg":"Invalid symbol."}`, ErrInvalidSymbol},
+		// Retry-After 0 keeps the pause it causes from holding up other tests
+		{"rate limited", http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}}, `{"code":-1003,"msg":"Too many requests."}`, ErrRateLimited},
+		{"banned", http.StatusTeapot, http.Header{"Retry-After": {"0"}}, `{"code":-1003,"msg":"Way too many requests."}`, ErrRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				for key, values := range tt.header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			if _, err := GetPrice("BTCUSDT"); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPriceNon200IsNotTyped(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	_, err := GetPrice("BTCUSDT")
+	if err == nil || errors.Is(err, ErrInvalidSymbol) || errors.Is(err, ErrRateLimited) {
+		t.Errorf("error = %v, want a plain API error", err)
+	}
+}
+
+func TestGetPriceTimeout(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	oldTimeout := rest.Load().client.Timeout
+	SetHTTPTimeout(20 * time.Millisecond)
+	t.Cleanup(func() { SetHTTPTimeout(oldTimeout) })
+
+	_, err := GetPrice("BTCUSDT")
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("error = %v, want a timeout", err)
+	}
+}
+
+func TestGetPricesCustomEndpoint(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/proxy/price" {
+			t.Errorf("unexpected request %s, want one to the custom endpoint", r.URL)
+		}
+		symbol := r.URL.Query().Get("s")
+		w.Write([]byte(`{"symbol":"` + symbol + `","price":"1.5"}`))
+	})
+	SetPriceEndpoint("/proxy/price?s=%s")
+	t.Cleanup(func() { SetPriceEndpoint(DefaultPriceEndpoint) })
+
+	prices, err := GetPrices([]string{"BTCUSDT", "ETHUSDT"})
+	if err != nil {
+		t.Fatalf("GetPrices: %v", err)
+	}
+	if len(prices) != 2 || prices["BTCUSDT"] != "1.5" || prices["ETHUSDT"] != "1.5" {
+		t.Errorf("prices = %v, want both symbols at 1.5", prices)
+	}
+}
+
+func TestGetKlinesCancelled(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := GetKlines(ctx, "BTCUSDT", "1m", 60); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestGetKlinesEscapesSymbol(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("symbol") != "BTC&limit=1" || query.Get("limit") != "60" {
+			t.Errorf("unexpected query %s, want the symbol kept whole", r.URL.RawQuery)
+		}
+		w.Write([]byte("[]"))
+	})
+
+	if _, err := GetKlines(context.Background(), "BTC&limit=1", "1m", 60); err != nil {
+		t.Errorf("GetKlines: %v", err)
+	}
+}
+
+func TestGetTicker24hCancelled(t *testing.T) {
+	requests := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GetTicker24h(ctx, "BTCUSDT"); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if requests != 0 {
+		t.Errorf("server got %d requests, want 0", requests)
+	}
+}
+
+func TestGetTicker24hEscapesSymbol(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("symbol"); got != "BTC&type=MINI" {
+			t.Errorf("symbol = %q in %s, want it kept whole", got, r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"symbol":"BTC&type=MINI","priceChangePercent":"1.5","highPrice":"2","lowPrice":"1","volume":"10"}`))
+	})
+
+	if _, err := GetTicker24h(context.Background(), "BTC&type=MINI"); err != nil {
+		t.Errorf("GetTicker24h: %v", err)
+	}
+}
+
+func TestGetBookTickerCancelled(t *testing.T) {
+	requests := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := GetBookTicker(ctx, "BTCUSDT"); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if requests != 0 {
+		t.Errorf("server got %d requests, want 0", requests)
+	}
+}
+
+func TestGetBookTickerEscapesSymbol(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("symbol"); got != "BTC&symbols=[]" {
+			t.Errorf("symbol = %q in %s, want it kept whole", got, r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"symbol":"BTCUSDT","bidPrice":"1","askPrice":"2"}`))
+	})
+
+	if _, _, err := GetBookTicker(context.Background(), "BTC&symbols=[]"); err != nil {
+		t.Errorf("GetBookTicker: %v", err)
+	}
+}
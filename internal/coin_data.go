@@ -20,7 +20,27 @@ type CoinInfo struct {
 	LastPrice     string       `json:"last_price"`
 	PreviousPrice string       `json:"previous_price"`
 	PriceHistory  []PricePoint `json:"price_history"`
+	Pinned        bool         `json:"pinned"`
+	PriceDecimals *int         `json:"price_decimals,omitempty"` // from the exchange tick size, nil until known
+	Holdings      float64      `json:"holdings,omitempty"`       // quantity held, for the portfolio total
+	SessionHigh   float64      `json:"session_high,omitempty"`   // highest price seen since the coin was added
+	SessionLow    float64      `json:"session_low,omitempty"`    // lowest price seen since the coin was added
+	Delisted      bool         `json:"delisted,omitempty"`       // no longer trading, only rechecked now and then
 	DisplayStr    string       `json:"-"`
 	FetchError    error        `json:"-"`
 	IsLoading     bool         `json:"-"`
+	Ticker24h     *Ticker24h   `json:"-"` // nil until the first successful 24h ticker fetch
+	ChangedAt     time.Time    `json:"-"` // when LastPrice last moved, for the tick flash
+	ChangeDir     int          `json:"-"` // 1 if that move was up, -1 if down
+	CheckedAt     time.Time    `json:"-"` // last recheck of a delisted coin
+	NextUpdate    time.Time    `json:"-"` // when polling fetches the coin next
+}
+
+// Decimals returns how many decimals to show for the coin's price, falling
+// back to PricePrecision until the exchange's tick size is known
+func (c *CoinInfo) Decimals() int {
+	if c.PriceDecimals != nil {
+		return *c.PriceDecimals
+	}
+	return PricePrecision()
 }
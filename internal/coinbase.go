@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var coinbaseURL = "https://api.coinbase.com"
+
+// Quote assets recognised when splitting a Binance style symbol, longest first
+var knownQuotes = []string{"USDT", "USDC", "FDUSD", "BUSD", "USD", "EUR", "GBP", "TRY", "BTC", "ETH", "BNB"}
+
+type coinbaseSpotResponse struct {
+	Data struct {
+		Base     string `json:"base"`
+		Currency string `json:"currency"`
+		Amount   string `json:"amount"`
+	} `json:"data"`
+}
+
+// CoinbaseSource serves spot prices from Coinbase. It has no klines, 24h
+// statistics or order book, so those return ErrUnsupported.
+type CoinbaseSource struct{}
+
+func (CoinbaseSource) Name() string {
+	return "coinbase"
+}
+
+// SplitSymbol splits a Binance style symbol like "ETHBTC" into its base and
+// quote assets by matching the end against knownQuotes. ok is false when no
+// known quote matches; exchange info should be preferred when it's loaded.
+func SplitSymbol(symbol string) (base, quote string, ok bool) {
+	symbol = strings.ToUpper(symbol)
+	for _, quote := range knownQuotes {
+		if strings.HasSuffix(symbol, quote) && len(symbol) > len(quote) {
+			return strings.TrimSuffix(symbol, quote), quote, true
+		}
+	}
+	return "", "", false
+}
+
+// coinbasePair maps a Binance symbol like "BTCUSDT" to Coinbase's "BTC-USD".
+// Coinbase quotes in fiat, so USD pegged stablecoins are mapped to USD.
+func coinbasePair(symbol string) (string, error) {
+	base, quote, ok := SplitSymbol(symbol)
+	if !ok {
+		return "", fmt.Errorf("unknown quote asset [%s]", symbol)
+	}
+	switch quote {
+	case "USDT", "USDC", "FDUSD", "BUSD":
+		quote = "USD"
+	}
+	return base + "-" + quote, nil
+}
+
+func (CoinbaseSource) GetPrice(ctx context.Context, symbol string) (string, error) {
+	pair, err := coinbasePair(symbol)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v2/prices/%s/spot", coinbaseURL, pair), nil)
+	if err != nil {
+		return "", fmt.Errorf("request build failed [%s]: %w", symbol, err)
+	}
+
+	resp, err := rest.Load().client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("HTTP request failed [%s]: %w", symbol, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", apiError(fmt.Sprintf("API error [%s]", symbol), resp, bodyBytes)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("body read error [%s]: %w", symbol, err)
+	}
+
+	var spotResp coinbaseSpotResponse
+	if err := json.Unmarshal(body, &spotResp); err != nil {
+		return "", fmt.Errorf("JSON parse error [%s]: %w, Received Data: %s", symbol, err, string(body))
+	}
+
+	if _, err := strconv.ParseFloat(spotResp.Data.Amount, 64); err != nil {
+		return "", fmt.Errorf("invalid price format [%s]: %w, Received Price: %s", symbol, err, spotResp.Data.Amount)
+	}
+
+	return spotResp.Data.Amount, nil
+}
+
+// GetPrices has no batch endpoint to use, so it fetches symbols one by one
+// and fails if any of them fails, like the Binance batch request
+func (s CoinbaseSource) GetPrices(ctx context.Context, symbols []string) (map[string]string, error) {
+	prices := make(map[string]string, len(symbols))
+	for _, symbol := range symbols {
+		price, err := s.GetPrice(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+		prices[symbol] = price
+	}
+	return prices, nil
+}
+
+func (CoinbaseSource) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	return nil, ErrUnsupported
+}
+
+func (CoinbaseSource) GetTicker24h(ctx context.Context, symbol string) (Ticker24h, error) {
+	return Ticker24h{}, ErrUnsupported
+}
+
+func (CoinbaseSource) GetBookTicker(ctx context.Context, symbol string) (bid, ask string, err error) {
+	return "", "", ErrUnsupported
+}
+
+func (CoinbaseSource) GetExchangeInfo(symbols ...string) (map[string]SymbolInfo, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,45 @@
+package internal
+
+import "testing"
+
+func TestSplitSymbol(t *testing.T) {
+	tests := []struct {
+		symbol      string
+		base, quote string
+		ok          bool
+	}{
+		{"BTCUSDT", "BTC", "USDT", true},
+		{"ETHBTC", "ETH", "BTC", true},
+		{"BNBFDUSD", "BNB", "FDUSD", true},
+		{"BTCUSD", "BTC", "USD", true},
+		{"ETHUSDC", "ETH", "USDC", true},
+		{"BTCEUR", "BTC", "EUR", true},
+		{"SOLBNB", "SOL", "BNB", true},
+		{"solusdt", "SOL", "USDT", true},
+		{"USDT", "", "", false},
+		{"BTCXYZ", "", "", false},
+	}
+	for _, tt := range tests {
+		base, quote, ok := SplitSymbol(tt.symbol)
+		if base != tt.base || quote != tt.quote || ok != tt.ok {
+			t.Errorf("SplitSymbol(%q) = %q, %q, %v, want %q, %q, %v", tt.symbol, base, quote, ok, tt.base, tt.quote, tt.ok)
+		}
+	}
+}
+
+func TestCoinbasePair(t *testing.T) {
+	tests := []struct{ symbol, want string }{
+		{"BTCUSDT", "BTC-USD"},
+		{"BNBFDUSD", "BNB-USD"},
+		{"ETHBTC", "ETH-BTC"},
+		{"BTCEUR", "BTC-EUR"},
+	}
+	for _, tt := range tests {
+		if got, err := coinbasePair(tt.symbol); err != nil || got != tt.want {
+			t.Errorf("coinbasePair(%q) = %q, %v, want %q", tt.symbol, got, err, tt.want)
+		}
+	}
+	if _, err := coinbasePair("BTCXYZ"); err == nil {
+		t.Error("coinbasePair accepted an unknown quote asset")
+	}
+}
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SymbolInfo is the part of a symbol's exchangeInfo entry the app uses
+type SymbolInfo struct {
+	Symbol        string
+	Status        string // "TRADING" when the pair is live
+	BaseAsset     string
+	QuoteAsset    string
+	PriceDecimals int // decimals of the PRICE_FILTER tick size
+}
+
+// The full exchangeInfo response is several megabytes, so it gets a more
+// generous timeout than the price requests
+var ExchangeInfoTimeout = 30 * time.Second
+
+type exchangeInfoResponse struct {
+	Symbols []struct {
+		Symbol     string `json:"symbol"`
+		Status     string `json:"status"`
+		BaseAsset  string `json:"baseAsset"`
+		QuoteAsset string `json:"quoteAsset"`
+		Filters    []struct {
+			FilterType string `json:"filterType"`
+			TickSize   string `json:"tickSize"`
+		} `json:"filters"`
+	} `json:"symbols"`
+}
+
+// GetExchangeInfo returns the exchange's symbol info keyed by symbol, for the
+// given symbols or for every symbol when none are given
+func GetExchangeInfo(symbols ...string) (map[string]SymbolInfo, error) {
+	query := ""
+	if len(symbols) > 0 {
+		symbolsJSON, err := json.Marshal(symbols)
+		if err != nil {
+			return nil, fmt.Errorf("symbol list encode error: %w", err)
+		}
+		query = "?symbols=" + url.QueryEscape(string(symbolsJSON))
+	}
+
+	settings := rest.Load()
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v3/exchangeInfo%s", settings.apiURL, query), nil)
+	if err != nil {
+		return nil, fmt.Errorf("exchangeInfo request build failed: %w", err)
+	}
+
+	resp, err := doRequestWith(&http.Client{Timeout: max(settings.client.Timeout, ExchangeInfoTimeout)}, req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP exchangeInfo request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, apiError("API exchangeInfo error", resp, bodyBytes)
+	}
+
+	var infoResp exchangeInfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&infoResp); err != nil {
+		return nil, fmt.Errorf("exchangeInfo JSON parse error: %w", err)
+	}
+
+	infos := make(map[string]SymbolInfo, len(infoResp.Symbols))
+	for _, s := range infoResp.Symbols {
+		info := SymbolInfo{
+			Symbol:        s.Symbol,
+			Status:        s.Status,
+			BaseAsset:     s.BaseAsset,
+			QuoteAsset:    s.QuoteAsset,
+			PriceDecimals: -1,
+		}
+		for _, filter := range s.Filters {
+			if filter.FilterType == "PRICE_FILTER" {
+				info.PriceDecimals = decimalsFromTickSize(filter.TickSize)
+			}
+		}
+		infos[s.Symbol] = info
+	}
+
+	return infos, nil
+}
+
+// decimalsFromTickSize counts the significant decimals of a tick size such as
+// "0.01000000" (2) or "1.00000000" (0), returning -1 if it can't be read
+func decimalsFromTickSize(tickSize string) int {
+	if tickSize == "" {
+		return -1
+	}
+	_, fraction, found := strings.Cut(tickSize, ".")
+	if !found {
+		return 0
+	}
+	return len(strings.TrimRight(fraction, "0"))
+}
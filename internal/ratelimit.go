@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultRequestsPerMinute stays well under Binance's request weight limit
+const DefaultRequestsPerMinute = 600
+
+// rateLimiter is a token bucket refilled at rate tokens per second, holding
+// up to burst tokens. pausedUntil fails every request after a 429.
+type rateLimiter struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// LimiterState is a snapshot of the outbound request limiter
+type LimiterState struct {
+	Tokens      float64
+	Burst       float64
+	PausedUntil time.Time
+}
+
+// Throttled reports whether requests are currently being held back
+func (s LimiterState) Throttled() bool {
+	return s.Tokens < 1 || time.Now().Before(s.PausedUntil)
+}
+
+var limiter = newRateLimiter(DefaultRequestsPerMinute)
+
+func newRateLimiter(requestsPerMinute int) *rateLimiter {
+	l := &rateLimiter{}
+	l.setRate(requestsPerMinute)
+	l.tokens = l.burst
+	return l
+}
+
+// setRate allows requestsPerMinute with bursts of up to a tenth of that
+func (l *rateLimiter) setRate(requestsPerMinute int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = float64(requestsPerMinute) / 60
+	l.burst = max(1, float64(requestsPerMinute)/10)
+	l.tokens = min(l.tokens, l.burst)
+	l.last = time.Now()
+}
+
+// refill adds the tokens earned since the last call. Callers must hold l.mu.
+func (l *rateLimiter) refill(now time.Time) {
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
+
+// Wait blocks until a token is available or ctx is done. While requests are
+// paused it fails at once with ErrRateLimited instead, as the pause can be
+// far longer than callers' retry budgets.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		if until := l.pausedUntil; now.Before(until) {
+			l.mu.Unlock()
+			return fmt.Errorf("%w: requests paused for %s", ErrRateLimited, until.Sub(now).Round(time.Second))
+		}
+		l.refill(now)
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// pause fails every request for d, never shortening an existing pause
+func (l *rateLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+func (l *rateLimiter) state() LimiterState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	return LimiterState{Tokens: l.tokens, Burst: l.burst, PausedUntil: l.pausedUntil}
+}
+
+// SetRateLimit caps outbound REST requests at requestsPerMinute
+func SetRateLimit(requestsPerMinute int) {
+	limiter.setRate(requestsPerMinute)
+}
+
+// RateLimitState returns the current state of the outbound request limiter
+func RateLimitState() LimiterState {
+	return limiter.state()
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an
+// HTTP date, defaulting to fallback when it's missing or malformed
+func retryAfter(header string, fallback time.Duration) time.Duration {
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return fallback
+}
+
+// doRequest sends req once a limiter token is available. A 429 (or Binance's
+// 418 ban) pauses every request for the server's Retry-After, and requests
+// made during the pause fail with ErrRateLimited without being sent.
+func doRequest(req *http.Request) (*http.Response, error) {
+	return doRequestWith(rest.Load().client, req)
+}
+
+// doRequestWith is doRequest using c, for requests that need a different timeout
+func doRequestWith(c *http.Client, req *http.Request) (*http.Response, error) {
+	if err := limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		limiter.pause(retryAfter(resp.Header.Get("Retry-After"), time.Minute))
+	}
+	return resp, nil
+}
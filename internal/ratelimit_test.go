@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetPriceWhilePaused(t *testing.T) {
+	requests := 0
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"symbol":"BTCUSDT","price":"67000"}`))
+	})
+	// Long enough that a retry sleep would show in the elapsed time
+	RetryBaseDelay = time.Second
+	limiter.pause(time.Minute)
+	t.Cleanup(func() {
+		limiter.mu.Lock()
+		limiter.pausedUntil = time.Time{}
+		limiter.mu.Unlock()
+	})
+
+	start := time.Now()
+	_, err := GetPriceContext(context.Background(), "BTCUSDT")
+
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("error = %v, want ErrRateLimited", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("took %s, want an immediate return", elapsed)
+	}
+	if requests != 0 {
+		t.Errorf("server got %d requests during the pause, want 0", requests)
+	}
+}
+
+func TestLimiterWaitsForToken(t *testing.T) {
+	l := newRateLimiter(600) // a token every 100ms, burst of 60
+	for range 60 {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+
+	// The bucket is empty, so the next token is a refill away
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait on an empty bucket = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ReplaySource plays back prices from a CSV file written by the export
+// feature instead of asking an exchange, for demos and running offline. The
+// recording runs speed times faster than real time and, once it's over,
+// either loops or holds the last prices.
+type ReplaySource struct {
+	series  map[string][]PricePoint // each symbol's points, oldest first
+	first   time.Time
+	last    time.Time
+	started time.Time
+	speed   float64
+	loop    bool
+}
+
+// LoadReplay reads a timestamp,symbol,price CSV into a ReplaySource. The
+// replay clock starts right away.
+func LoadReplay(filename string, speed float64, loop bool) (*ReplaySource, error) {
+	if speed <= 0 {
+		return nil, fmt.Errorf("replay speed must be positive, got %g", speed)
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay file: %w", err)
+	}
+	defer file.Close()
+
+	r := &ReplaySource{series: make(map[string][]PricePoint), speed: speed, loop: loop}
+	reader := csv.NewReader(file)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read replay file: %w", err)
+		}
+		if len(record) < 3 || (line == 1 && record[0] == "timestamp") {
+			continue
+		}
+		timestamp, err := time.Parse(time.RFC3339Nano, record[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp on line %d: %w", line, err)
+		}
+		price, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price on line %d: %w", line, err)
+		}
+		r.series[record[1]] = append(r.series[record[1]], PricePoint{Price: price, Timestamp: timestamp})
+	}
+	if len(r.series) == 0 {
+		return nil, fmt.Errorf("replay file %s has no prices", filename)
+	}
+
+	for symbol, points := range r.series {
+		sort.SliceStable(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+		if r.first.IsZero() || points[0].Timestamp.Before(r.first) {
+			r.first = points[0].Timestamp
+		}
+		if end := points[len(points)-1].Timestamp; end.After(r.last) {
+			r.last = end
+		}
+		r.series[symbol] = points
+	}
+	r.started = time.Now()
+	return r, nil
+}
+
+// Symbols returns the replayed symbols, sorted
+func (r *ReplaySource) Symbols() []string {
+	symbols := make([]string, 0, len(r.series))
+	for symbol := range r.series {
+		symbols = append(symbols, symbol)
+	}
+	slices.Sort(symbols)
+	return symbols
+}
+
+// now is the point in the recording the replay has reached
+func (r *ReplaySource) now() time.Time {
+	elapsed := time.Duration(float64(time.Since(r.started)) * r.speed)
+	if length := r.last.Sub(r.first); r.loop && length > 0 {
+		elapsed %= length
+	}
+	return r.first.Add(elapsed)
+}
+
+// priceAt returns symbol's latest recorded price at t, or its first one
+// before the symbol's recording starts
+func (r *ReplaySource) priceAt(symbol string, t time.Time) (string, bool) {
+	points, ok := r.series[symbol]
+	if !ok {
+		return "", false
+	}
+	i := sort.Search(len(points), func(i int) bool { return points[i].Timestamp.After(t) })
+	return strconv.FormatFloat(points[max(i-1, 0)].Price, 'f', -1, 64), true
+}
+
+func (r *ReplaySource) Name() string {
+	return "replay"
+}
+
+func (r *ReplaySource) GetPrice(ctx context.Context, symbol string) (string, error) {
+	price, ok := r.priceAt(symbol, r.now())
+	if !ok {
+		return "", fmt.Errorf("%w: %s is not in the replay", ErrInvalidSymbol, symbol)
+	}
+	return price, nil
+}
+
+func (r *ReplaySource) GetPrices(ctx context.Context, symbols []string) (map[string]string, error) {
+	now := r.now()
+	prices := make(map[string]string, len(symbols))
+	for _, symbol := range symbols {
+		if price, ok := r.priceAt(symbol, now); ok {
+			prices[symbol] = price
+		}
+	}
+	return prices, nil
+}
+
+func (r *ReplaySource) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	return nil, ErrUnsupported
+}
+
+func (r *ReplaySource) GetTicker24h(ctx context.Context, symbol string) (Ticker24h, error) {
+	return Ticker24h{}, ErrUnsupported
+}
+
+func (r *ReplaySource) GetBookTicker(ctx context.Context, symbol string) (bid, ask string, err error) {
+	return "", "", ErrUnsupported
+}
+
+func (r *ReplaySource) GetExchangeInfo(symbols ...string) (map[string]SymbolInfo, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrUnsupported = errors.New("not supported by this price source")
+
+// ErrInvalidSymbol is returned for symbols the exchange doesn't know. Unlike
+// network errors it's permanent, so retrying won't help.
+var ErrInvalidSymbol = errors.New("invalid symbol")
+
+// ErrRateLimited is returned when the exchange rejects a request for going
+// over its rate limit. Every request is held back until it lifts.
+var ErrRateLimited = errors.New("rate limited")
+
+// PriceSource is where the app gets its market data from. Symbols are always
+// in Binance form (e.g. "BTCUSDT"); sources map them to their own format.
+type PriceSource interface {
+	Name() string
+	GetPrice(ctx context.Context, symbol string) (string, error)
+	GetPrices(ctx context.Context, symbols []string) (map[string]string, error)
+	GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error)
+	GetTicker24h(ctx context.Context, symbol string) (Ticker24h, error)
+	GetBookTicker(ctx context.Context, symbol string) (bid, ask string, err error)
+	GetExchangeInfo(symbols ...string) (map[string]SymbolInfo, error)
+}
+
+// BinanceSource serves data from the Binance REST API at the configured apiURL
+type BinanceSource struct{}
+
+func (BinanceSource) Name() string {
+	return "binance"
+}
+
+func (BinanceSource) GetPrice(ctx context.Context, symbol string) (string, error) {
+	return GetPriceContext(ctx, symbol)
+}
+
+func (BinanceSource) GetPrices(ctx context.Context, symbols []string) (map[string]string, error) {
+	return GetPricesContext(ctx, symbols)
+}
+
+func (BinanceSource) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	return GetKlines(ctx, symbol, interval, limit)
+}
+
+func (BinanceSource) GetTicker24h(ctx context.Context, symbol string) (Ticker24h, error) {
+	return GetTicker24h(ctx, symbol)
+}
+
+func (BinanceSource) GetBookTicker(ctx context.Context, symbol string) (bid, ask string, err error) {
+	return GetBookTicker(ctx, symbol)
+}
+
+func (BinanceSource) GetExchangeInfo(symbols ...string) (map[string]SymbolInfo, error) {
+	return GetExchangeInfo(symbols...)
+}
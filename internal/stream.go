@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var streamURL = "wss://stream.binance.com:9443"
+
+// Reconnect backoff for PriceStream: starts at StreamBaseDelay and doubles up to StreamMaxDelay
+var StreamBaseDelay = 1 * time.Second
+var StreamMaxDelay = 30 * time.Second
+
+type PriceUpdate struct {
+	Symbol string
+	Price  string
+}
+
+type streamMessage struct {
+	Stream string `json:"stream"`
+	Data   struct {
+		Symbol string `json:"s"`
+		Price  string `json:"p"`
+	} `json:"data"`
+}
+
+// PriceStream pushes live trade prices from Binance's combined trade stream
+// onto Updates, reconnecting with backoff whenever the socket drops
+type PriceStream struct {
+	Updates   chan PriceUpdate
+	symbols   []string
+	connected atomic.Bool
+}
+
+func NewPriceStream(symbols []string) *PriceStream {
+	return &PriceStream{
+		Updates: make(chan PriceUpdate, 256),
+		symbols: symbols,
+	}
+}
+
+// Connected reports whether the socket is currently up
+func (s *PriceStream) Connected() bool {
+	return s.connected.Load()
+}
+
+// Run keeps the stream connected until ctx is cancelled
+func (s *PriceStream) Run(ctx context.Context) {
+	delay := StreamBaseDelay
+	for ctx.Err() == nil {
+		err := s.runOnce(ctx)
+		// A connection that got through starts the backoff over
+		if s.connected.Swap(false) {
+			delay = StreamBaseDelay
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Price stream disconnected, reconnecting in %s: %v", delay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, StreamMaxDelay)
+	}
+}
+
+func (s *PriceStream) runOnce(ctx context.Context) error {
+	streams := make([]string, len(s.symbols))
+	for i, symbol := range s.symbols {
+		streams[i] = strings.ToLower(symbol) + "@trade"
+	}
+
+	conn, err := dialWebSocket(fmt.Sprintf("%s/stream?streams=%s", streamURL, strings.Join(streams, "/")), 5*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock ReadMessage on shutdown
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	s.connected.Store(true)
+	log.Printf("Price stream connected for %d symbols", len(s.symbols))
+
+	for {
+		message, err := conn.ReadMessage(5 * time.Minute)
+		if err != nil {
+			return err
+		}
+
+		var msg streamMessage
+		if err := json.Unmarshal(message, &msg); err != nil {
+			log.Printf("Could not parse stream message: %v, Received Data: %s", err, string(message))
+			continue
+		}
+		if msg.Data.Symbol == "" || msg.Data.Price == "" {
+			continue
+		}
+
+		// Drop updates rather than stall the socket if the UI falls behind
+		select {
+		case s.Updates <- PriceUpdate{Symbol: msg.Data.Symbol, Price: msg.Data.Price}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"main/internal"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/esset/v2"
+)
+
+// How long the on-screen alert banner stays visible
+const bannerDuration = 8 * time.Second
+
+type Alert struct {
+	Symbol    string  `json:"symbol"`
+	Direction string  `json:"direction"` // "above" or "below"
+	Target    float64 `json:"target"`
+	Triggered bool    `json:"triggered"`
+	Repeat    bool    `json:"repeat"` // re-arm once the price moves back across the target
+}
+
+// crossed reports whether price is on the alert's side of the target
+func (a *Alert) crossed(price float64) bool {
+	if a.Direction == "above" {
+		return price >= a.Target
+	}
+	return price <= a.Target
+}
+
+// checkAlerts fires pending alerts for symbol that price has crossed.
+// Callers must hold g.mu.
+func (g *Game) checkAlerts(symbol string, price float64) {
+	for _, alert := range g.alerts {
+		if alert.Symbol != symbol {
+			continue
+		}
+
+		if alert.Triggered {
+			if alert.Repeat && !alert.crossed(price) {
+				alert.Triggered = false
+			}
+			continue
+		}
+		if !alert.crossed(price) {
+			continue
+		}
+
+		alert.Triggered = true
+		message := fmt.Sprintf("%s is %s %s (now %s)", symbol, alert.Direction,
+			strconv.FormatFloat(alert.Target, 'f', -1, 64), formatNumber(price, internal.PricePrecision()))
+		log.Printf("Alert: %s", message)
+		g.banner = message
+		g.bannerUntil = time.Now().Add(bannerDuration)
+		g.playAlertSound()
+		go notify("Price alert", message)
+	}
+}
+
+// submitAlertInput creates an alert for the selected coin at the typed price.
+// The direction is whichever side of the current price the target is on.
+func (g *Game) submitAlertInput(repeat bool) {
+	target, err := strconv.ParseFloat(g.alertInput.Text, 64)
+	g.alertInput.Text = ""
+	g.alertInput.Focused = false
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err != nil || target <= 0 {
+		g.statusText = "Invalid alert price"
+		return
+	}
+	if g.SelectedCoinIndex < 0 || g.SelectedCoinIndex >= len(g.coinData) {
+		return
+	}
+	coin := g.coinData[g.SelectedCoinIndex]
+	last, err := strconv.ParseFloat(coin.LastPrice, 64)
+	if err != nil {
+		g.statusText = "No price yet for alert"
+		return
+	}
+
+	direction := "above"
+	if target < last {
+		direction = "below"
+	}
+	g.alerts = append(g.alerts, &Alert{
+		Symbol:    coin.Symbol,
+		Direction: direction,
+		Target:    target,
+		Repeat:    repeat,
+	})
+	g.dirty = true
+	g.statusText = fmt.Sprintf("Alert: %s %s %s", coin.Symbol, direction, strconv.FormatFloat(target, 'f', -1, 64))
+	log.Printf("Added alert for %s %s %f (repeat: %t)", coin.Symbol, direction, target, repeat)
+}
+
+// drawBanner shows the latest fired alert across the top of the chart.
+// Callers must hold g.mu.
+func (g *Game) drawBanner(screen *ebiten.Image, left, top, width float64) {
+	if g.banner == "" || time.Now().After(g.bannerUntil) {
+		return
+	}
+	vector.DrawFilledRect(screen, float32(left), float32(top), float32(width), float32(g.physicalLineHeight+12), color.RGBA{200, 140, 0, 230}, false)
+	esset.DrawText(screen, g.banner, 0, left+12, top+6, g.fontFace, color.RGBA{20, 20, 20, 255})
+}
+
+// notify shows a desktop notification using the platform's own tooling
+func notify(title, message string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("notify-send", title, message)
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", message, title))
+	case "windows":
+		// The text goes in through the environment, never into the script
+		// itself, so quotes in it can't end a string and run as code
+		script := `[reflection.assembly]::loadwithpartialname('System.Windows.Forms') | Out-Null;` +
+			`$n = New-Object System.Windows.Forms.NotifyIcon; $n.Icon = [System.Drawing.SystemIcons]::Information;` +
+			`$n.Visible = $true; $n.ShowBalloonTip(5000, $env:EBICRYPTO_TITLE, $env:EBICRYPTO_MESSAGE, 'Info'); Start-Sleep -Seconds 6; $n.Dispose()`
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+		cmd.Env = append(os.Environ(), "EBICRYPTO_TITLE="+title, "EBICRYPTO_MESSAGE="+message)
+	default:
+		return
+	}
+
+	if err := cmd.Run(); err != nil {
+		log.Printf("Could not show desktop notification: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Height of the overview strip under the line chart, before device scaling
+const overviewBaseHeight = 40
+
+// How close to a brush edge, in pixels, a press grabs the edge
+const brushHandleWidth = 6
+
+// brushPart is the part of the overview brush a drag moves
+type brushPart int
+
+const (
+	brushNone brushPart = iota
+	brushLeft
+	brushRight
+	brushBody
+	brushNew // dragging out a fresh range from brushGrab
+)
+
+// brushBounds returns the pan/zoom window as fractions, 0..1 when showing
+// the full range. Callers must hold g.mu.
+func (g *Game) brushBounds() (start, end float64) {
+	if g.brushEnd <= g.brushStart {
+		return 0, 1
+	}
+	return g.brushStart, g.brushEnd
+}
+
+// drawOverview draws the whole timeline window of the selected coin in area
+// with the brush over the part the chart shows. Callers must hold g.mu.
+func (g *Game) drawOverview(screen *ebiten.Image, area chartRect) {
+	vector.DrawFilledRect(screen, float32(area.left), float32(area.top), float32(area.width), float32(area.height), g.theme.Card, false)
+	vector.StrokeRect(screen, float32(area.left), float32(area.top), float32(area.width), float32(area.height), 1, g.theme.Border, false)
+
+	points := g.visiblePoints()
+	if len(points) < 2 {
+		return
+	}
+	g.drawSparkline(screen, chartRect{area.left, area.top + 4, area.width, area.height - 8}, points, g.theme.Accent)
+
+	// Dim what's outside the brush, then outline it with grab handles
+	start, end := g.brushBounds()
+	left := area.left + start*area.width
+	right := area.left + end*area.width
+	shade := color.RGBA{0, 0, 0, 90}
+	vector.DrawFilledRect(screen, float32(area.left), float32(area.top), float32(left-area.left), float32(area.height), shade, false)
+	vector.DrawFilledRect(screen, float32(right), float32(area.top), float32(area.left+area.width-right), float32(area.height), shade, false)
+	vector.StrokeRect(screen, float32(left), float32(area.top), float32(right-left), float32(area.height), 1.5, g.theme.TextSecondary, false)
+	for _, x := range []float64{left, right} {
+		vector.DrawFilledRect(screen, float32(x-2), float32(area.top+area.height/4), 4, float32(area.height/2), g.theme.TextPrimary, false)
+	}
+}
+
+// handleBrushInput drags the brush edges or body, or a new range when the
+// press lands outside it, and reports whether the overview took the input.
+// Callers must hold g.mu.
+func (g *Game) handleBrushInput(mx, my int) bool {
+	area := g.overviewArea
+	n := len(g.visiblePoints())
+	if area.width <= 0 || n < 2 {
+		g.brushDrag = brushNone
+		return false
+	}
+	frac := min(max((float64(mx)-area.left)/area.width, 0), 1)
+	start, end := g.brushBounds()
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		inStrip := float64(mx) >= area.left && float64(mx) <= area.left+area.width &&
+			float64(my) >= area.top && float64(my) <= area.top+area.height
+		if !inStrip {
+			return false
+		}
+		handle := brushHandleWidth / area.width
+		switch {
+		case frac >= start-handle && frac <= start+handle && start > 0:
+			g.brushDrag = brushLeft
+		case frac >= end-handle && frac <= end+handle && end < 1:
+			g.brushDrag = brushRight
+		case frac > start && frac < end && end-start < 1:
+			g.brushDrag = brushBody
+			g.brushGrab = frac - start
+		default:
+			g.brushDrag = brushNew
+			g.brushGrab = frac
+		}
+		return true
+	}
+
+	if g.brushDrag == brushNone {
+		return false
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		// A click without a drag, or a range narrower than the minimum, shows everything
+		if g.brushDrag == brushNew && g.brushEnd-g.brushStart < minViewPoints/float64(n) {
+			g.resetView()
+		}
+		g.brushDrag = brushNone
+		return true
+	}
+
+	minWidth := minViewPoints / float64(n)
+	switch g.brushDrag {
+	case brushLeft:
+		g.brushStart, g.brushEnd = min(frac, end-minWidth), end
+	case brushRight:
+		g.brushStart, g.brushEnd = start, max(frac, start+minWidth)
+	case brushBody:
+		width := end - start
+		g.brushStart = min(max(frac-g.brushGrab, 0), 1-width)
+		g.brushEnd = g.brushStart + width
+	case brushNew:
+		g.brushStart, g.brushEnd = min(g.brushGrab, frac), max(g.brushGrab, frac)
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// errNoClipboard is returned when no clipboard tool is available
+var errNoClipboard = errors.New("no clipboard tool found")
+
+// writeClipboard puts text on the system clipboard using the platform's own
+// tooling, like notify does for notifications
+func writeClipboard(text string) error {
+	cmd, err := clipboardCommand()
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// readClipboard returns the text on the system clipboard
+func readClipboard() (string, error) {
+	cmd, err := pasteCommand()
+	if err != nil {
+		return "", err
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// clipboardCommand returns the command that copies its stdin to the clipboard
+func clipboardCommand() (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("pbcopy"), nil
+	case "windows":
+		return exec.Command("clip"), nil
+	}
+
+	candidates := [][]string{
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append([][]string{{"wl-copy"}}, candidates...)
+	}
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate[0]); err == nil {
+			return exec.Command(candidate[0], candidate[1:]...), nil
+		}
+	}
+	return nil, errNoClipboard
+}
+
+// pasteCommand returns the command that prints the clipboard to its stdout
+func pasteCommand() (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("pbpaste"), nil
+	case "windows":
+		return exec.Command("powershell", "-NoProfile", "-Command", "Get-Clipboard"), nil
+	}
+
+	candidates := [][]string{
+		{"xclip", "-selection", "clipboard", "-o"},
+		{"xsel", "--clipboard", "--output"},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append([][]string{{"wl-paste", "--no-newline"}}, candidates...)
+	}
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate[0]); err == nil {
+			return exec.Command(candidate[0], candidate[1:]...), nil
+		}
+	}
+	return nil, errNoClipboard
+}
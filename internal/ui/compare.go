@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"main/internal"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/esset/v2"
+)
+
+// Line color of the coin overlaid in compare mode
+var compareColor = color.RGBA{230, 90, 200, 255}
+
+// compareCoin returns the coin overlaid on the selected one, or nil when
+// compare mode is off. Callers must hold g.mu.
+func (g *Game) compareCoin() *internal.CoinInfo {
+	if g.CompareCoinIndex < 0 || g.CompareCoinIndex >= len(g.coinData) || g.CompareCoinIndex == g.SelectedCoinIndex {
+		return nil
+	}
+	return g.coinData[g.CompareCoinIndex]
+}
+
+// toggleCompare overlays coin index on the chart, or turns compare mode off
+// when it's already overlaid. Callers must hold g.mu.
+func (g *Game) toggleCompare(index int) {
+	if index == g.CompareCoinIndex || index == g.SelectedCoinIndex {
+		g.CompareCoinIndex = -1
+		return
+	}
+	g.CompareCoinIndex = index
+	g.statusText = fmt.Sprintf("Comparing with %s", g.coinData[index].Symbol)
+}
+
+// alignSeries returns, for each of points' timestamps, the price of other at
+// or just before it, so two coins polled at slightly different times share x
+// positions. Timestamps before other's first point are NaN.
+func alignSeries(points, other []internal.PricePoint) []float64 {
+	aligned := make([]float64, len(points))
+	for i, pp := range points {
+		j := sort.Search(len(other), func(j int) bool {
+			return other[j].Timestamp.After(pp.Timestamp)
+		})
+		if j == 0 {
+			aligned[i] = math.NaN()
+			continue
+		}
+		aligned[i] = other[j-1].Price
+	}
+	return aligned
+}
+
+// normalizeSeries returns values as the percent change from the first
+// non-NaN value, (p/p0 - 1) * 100
+func normalizeSeries(values []float64) []float64 {
+	normalized := make([]float64, len(values))
+	base := math.NaN()
+	for i, v := range values {
+		if math.IsNaN(base) && !math.IsNaN(v) && v != 0 {
+			base = v
+		}
+		normalized[i] = (v/base - 1) * 100 // NaN until there's a base
+	}
+	return normalized
+}
+
+// drawCompareChart plots history and compare's prices over the same span as
+// percent change from the window start, with a legend of each one's current
+// change. Callers must hold g.mu.
+func (g *Game) drawCompareChart(screen *ebiten.Image, area chartRect, history []internal.PricePoint, compare *internal.CoinInfo, gridLines int) {
+	prices := make([]float64, len(history))
+	for i, pp := range history {
+		prices[i] = pp.Price
+	}
+	base := normalizeSeries(prices)
+	other := normalizeSeries(alignSeries(history, compare.PriceHistory))
+
+	// Keep 0% in range, which also keeps priceToY off the log scale path
+	low, high := 0.0, 0.0
+	for _, series := range [][]float64{base, other} {
+		for _, v := range series {
+			if !math.IsNaN(v) {
+				low, high = math.Min(low, v), math.Max(high, v)
+			}
+		}
+	}
+	if low == high {
+		low, high = -0.1, 0.1
+	}
+
+	g.drawValueAxis(screen, area, low, high, gridLines, func(percent float64) string {
+		return fmt.Sprintf("%+.2f%%", percent)
+	})
+	if len(history) > 1 {
+		g.drawTimeAxis(screen, area, history[0].Timestamp, history[len(history)-1].Timestamp)
+	}
+	zeroY := float32(area.top + area.height - g.priceToY(0, low, high)*area.height)
+	vector.StrokeLine(screen, float32(area.left), zeroY, float32(area.left+area.width), zeroY, 1, g.theme.TextMuted, false)
+
+	width := g.lineWidth()
+	g.strokeSeries(screen, area, base, low, high, width, g.theme.Accent)
+	g.strokeSeries(screen, area, other, low, high, width, compareColor)
+
+	selected := g.coinData[g.SelectedCoinIndex]
+	lastValue := func(series []float64) string {
+		for i := len(series) - 1; i >= 0; i-- {
+			if !math.IsNaN(series[i]) {
+				return fmt.Sprintf("%+.2f%%", series[i])
+			}
+		}
+		return "-"
+	}
+	legend := []struct {
+		label string
+		clr   color.RGBA
+	}{
+		{selected.Symbol + " " + lastValue(base), g.theme.Accent},
+		{compare.Symbol + " " + lastValue(other), compareColor},
+	}
+	for i, entry := range legend {
+		y := area.top + 10 + float64(i)*g.physicalLineHeight
+		vector.DrawFilledRect(screen, float32(area.left+10), float32(y+4), 10, 10, entry.clr, false)
+		esset.DrawText(screen, entry.label, 0, area.left+26, y, g.fontFace, g.theme.TextPrimary)
+	}
+}
@@ -0,0 +1,225 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"main/internal"
+	"net/url"
+	"os"
+	"time"
+)
+
+const configFilename = "config.json"
+
+// Range of the grid_lines setting
+const (
+	minGridLines = 4
+	maxGridLines = 12
+)
+
+// Range of the line_width setting, and the settings panel's step
+const (
+	minLineWidth  = 0.5
+	maxLineWidth  = 8.0
+	lineWidthStep = 0.5
+)
+
+type Config struct {
+	Source           string `json:"source"` // "binance" or "coinbase"
+	APIURL           string `json:"api_url"`
+	PriceEndpoint    string `json:"price_endpoint"` // price request path under api_url, %s for the symbol
+	HTTPTimeoutMs    int    `json:"http_timeout_ms"`
+	UpdateIntervalMs int    `json:"update_interval_ms"`
+	// Update interval of every coin but the selected one, which uses
+	// update_interval_ms
+	BackgroundIntervalMs int     `json:"background_interval_ms"`
+	PricePrecision       int     `json:"price_precision"`
+	Theme                string  `json:"theme"`        // "dark" or "light"
+	ColorScheme          string  `json:"color_scheme"` // "classic" green/red or "colorblind" blue/orange
+	RequestsPerMinute    int     `json:"requests_per_minute"`
+	AutosaveIntervalS    int     `json:"autosave_interval_s"`
+	NumberFormat         string  `json:"number_format"`     // "1,234.56" or "1.234,56"
+	GridLines            int     `json:"grid_lines"`        // chart grid divisions, 4-12
+	AlertSound           bool    `json:"alert_sound"`       // beep when a price alert fires
+	AlertVolume          int     `json:"alert_volume"`      // 0-100
+	KlineLimit           int     `json:"kline_limit"`       // candles requested per timeline, 1-1000, 0 for the timeline's default
+	MaxPlotPoints        int     `json:"max_plot_points"`   // line chart points drawn, history is downsampled past it
+	LineWidth            float64 `json:"line_width"`        // price line width in logical pixels, 0.5-8
+	PointMarkers         bool    `json:"point_markers"`     // dot every plotted point of the price line
+	AxisSide             string  `json:"axis_side"`         // price axis labels on the "left", "right" or "both"
+	ShowSpread           bool    `json:"show_spread"`       // bid/ask spread of the selected coin in the topbar
+	VolumeShading        bool    `json:"volume_shading"`    // fade candle bodies with low volume
+	YPaddingPercent      float64 `json:"y_padding_percent"` // room above and below the data on the price axis, as a percentage of its range, 0-50
+	AlwaysOnTop          bool    `json:"always_on_top"`     // keep the window above other apps
+	CompactMode          bool    `json:"compact_mode"`      // hide the chart in a window just tall enough for the coin list
+}
+
+func defaultConfig() Config {
+	return Config{
+		Source:               "binance",
+		APIURL:               "https://api.binance.com",
+		PriceEndpoint:        internal.DefaultPriceEndpoint,
+		HTTPTimeoutMs:        1000,
+		UpdateIntervalMs:     1000,
+		BackgroundIntervalMs: 5000,
+		PricePrecision:       3,
+		Theme:                "dark",
+		ColorScheme:          schemeClassic,
+		RequestsPerMinute:    internal.DefaultRequestsPerMinute,
+		AutosaveIntervalS:    30,
+		NumberFormat:         numberFormatComma,
+		GridLines:            6,
+		AlertSound:           true,
+		AlertVolume:          50,
+		MaxPlotPoints:        2000,
+		LineWidth:            2.5,
+		AxisSide:             axisLeft,
+		VolumeShading:        true,
+	}
+}
+
+// loadConfig reads filename over the defaults, so missing keys and a missing
+// file both fall back to them. Out of range values are replaced with defaults.
+func loadConfig(filename string) (Config, error) {
+	cfg := defaultConfig()
+
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(&cfg); err != nil {
+		return defaultConfig(), fmt.Errorf("failed to decode config file: %w", err)
+	}
+
+	cfg.validate()
+	log.Printf("Config loaded from %s", filename)
+	return cfg, nil
+}
+
+func (c *Config) validate() {
+	defaults := defaultConfig()
+
+	if c.Source != "binance" && c.Source != "coinbase" {
+		log.Printf("Warning: unknown source %q, using %q", c.Source, defaults.Source)
+		c.Source = defaults.Source
+	}
+	if !validAPIURL(c.APIURL) {
+		log.Printf("Warning: invalid api_url %q, using %q", c.APIURL, defaults.APIURL)
+		c.APIURL = defaults.APIURL
+	}
+	if !internal.ValidPriceEndpoint(c.PriceEndpoint) {
+		log.Printf("Warning: price_endpoint %q needs to be a path with exactly one %%s, using %q", c.PriceEndpoint, defaults.PriceEndpoint)
+		c.PriceEndpoint = defaults.PriceEndpoint
+	}
+	if c.HTTPTimeoutMs < 100 {
+		log.Printf("Warning: http_timeout_ms %d is below 100, using %d", c.HTTPTimeoutMs, defaults.HTTPTimeoutMs)
+		c.HTTPTimeoutMs = defaults.HTTPTimeoutMs
+	}
+	if c.UpdateIntervalMs < 100 {
+		log.Printf("Warning: update_interval_ms %d is below 100, using %d", c.UpdateIntervalMs, defaults.UpdateIntervalMs)
+		c.UpdateIntervalMs = defaults.UpdateIntervalMs
+	}
+	if c.BackgroundIntervalMs < 100 {
+		log.Printf("Warning: background_interval_ms %d is below 100, using %d", c.BackgroundIntervalMs, defaults.BackgroundIntervalMs)
+		c.BackgroundIntervalMs = defaults.BackgroundIntervalMs
+	}
+	if c.Theme != darkTheme.Name && c.Theme != lightTheme.Name {
+		log.Printf("Warning: unknown theme %q, using %q", c.Theme, defaults.Theme)
+		c.Theme = defaults.Theme
+	}
+	if c.ColorScheme != schemeClassic && c.ColorScheme != schemeColorblind {
+		log.Printf("Warning: unknown color_scheme %q, using %q", c.ColorScheme, defaults.ColorScheme)
+		c.ColorScheme = defaults.ColorScheme
+	}
+	if c.RequestsPerMinute < 1 {
+		log.Printf("Warning: requests_per_minute %d is below 1, using %d", c.RequestsPerMinute, defaults.RequestsPerMinute)
+		c.RequestsPerMinute = defaults.RequestsPerMinute
+	}
+	if c.AutosaveIntervalS < 1 {
+		log.Printf("Warning: autosave_interval_s %d is below 1, using %d", c.AutosaveIntervalS, defaults.AutosaveIntervalS)
+		c.AutosaveIntervalS = defaults.AutosaveIntervalS
+	}
+	if c.NumberFormat != numberFormatComma && c.NumberFormat != numberFormatDot {
+		log.Printf("Warning: unknown number_format %q, using %q", c.NumberFormat, defaults.NumberFormat)
+		c.NumberFormat = defaults.NumberFormat
+	}
+	if c.GridLines < minGridLines || c.GridLines > maxGridLines {
+		log.Printf("Warning: grid_lines %d is outside %d-%d, using %d", c.GridLines, minGridLines, maxGridLines, defaults.GridLines)
+		c.GridLines = defaults.GridLines
+	}
+	if c.AlertVolume < 0 || c.AlertVolume > 100 {
+		log.Printf("Warning: alert_volume %d is outside 0-100, using %d", c.AlertVolume, defaults.AlertVolume)
+		c.AlertVolume = defaults.AlertVolume
+	}
+	if c.KlineLimit < 0 || c.KlineLimit > maxKlineLimit {
+		log.Printf("Warning: kline_limit %d is outside 0-%d, using %d", c.KlineLimit, maxKlineLimit, defaults.KlineLimit)
+		c.KlineLimit = defaults.KlineLimit
+	}
+	if c.MaxPlotPoints < minPlotPoints {
+		log.Printf("Warning: max_plot_points %d is below %d, using %d", c.MaxPlotPoints, minPlotPoints, defaults.MaxPlotPoints)
+		c.MaxPlotPoints = defaults.MaxPlotPoints
+	}
+	if c.LineWidth < minLineWidth || c.LineWidth > maxLineWidth {
+		log.Printf("Warning: line_width %g is outside %g-%g, using %g", c.LineWidth, minLineWidth, maxLineWidth, defaults.LineWidth)
+		c.LineWidth = defaults.LineWidth
+	}
+	if c.AxisSide != axisLeft && c.AxisSide != axisRight && c.AxisSide != axisBoth {
+		log.Printf("Warning: unknown axis_side %q, using %q", c.AxisSide, defaults.AxisSide)
+		c.AxisSide = defaults.AxisSide
+	}
+	if c.YPaddingPercent < 0 || c.YPaddingPercent > maxYPaddingPercent {
+		log.Printf("Warning: y_padding_percent %g is outside 0-%g, using %g", c.YPaddingPercent, maxYPaddingPercent, defaults.YPaddingPercent)
+		c.YPaddingPercent = defaults.YPaddingPercent
+	}
+	if c.PricePrecision < 0 || c.PricePrecision > 8 {
+		log.Printf("Warning: price_precision %d is outside 0-8, using %d", c.PricePrecision, defaults.PricePrecision)
+		c.PricePrecision = defaults.PricePrecision
+	}
+}
+
+// validAPIURL reports whether s is an absolute http(s) URL
+func validAPIURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func saveConfig(cfg Config, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(cfg); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	log.Printf("Config saved to %s", filename)
+	return nil
+}
+
+// apply pushes the config into the internal package settings
+func (c Config) apply() {
+	internal.SetAPIURL(c.APIURL)
+	internal.SetPriceEndpoint(c.PriceEndpoint)
+	internal.SetHTTPTimeout(time.Duration(c.HTTPTimeoutMs) * time.Millisecond)
+	internal.SetUpdateInterval(time.Duration(c.UpdateIntervalMs) * time.Millisecond)
+	internal.SetPricePrecision(c.PricePrecision)
+	internal.SetRateLimit(c.RequestsPerMinute)
+	setNumberFormat(c.NumberFormat)
+}
+
+func newPriceSource(name string) internal.PriceSource {
+	if name == "coinbase" {
+		return internal.CoinbaseSource{}
+	}
+	return internal.BinanceSource{}
+}
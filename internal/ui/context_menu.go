@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"main/internal"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/esset/v2"
+)
+
+// ContextMenu is a popup list of actions opened by right-clicking a coin row.
+// Its rows share the dropdown option height.
+type ContextMenu struct {
+	Position image.Point // top left corner, kept on screen when drawn
+	Items    []MenuItem
+}
+
+type MenuItem struct {
+	Label  string
+	Action func()
+}
+
+// itemRect is where item i of menu is drawn and clicked
+func (g *Game) itemRect(menu *ContextMenu, i int) image.Rectangle {
+	width := 0.0
+	for _, item := range menu.Items {
+		w, _ := text.Measure(item.Label, g.fontFace, 0)
+		width = max(width, w)
+	}
+	top := menu.Position.Y + i*g.optionHeight()
+	return image.Rect(menu.Position.X, top, menu.Position.X+int(width)+24, top+g.optionHeight())
+}
+
+// openCoinMenu opens the context menu for coin at mx, my. Callers must hold g.mu.
+func (g *Game) openCoinMenu(coin *internal.CoinInfo, mx, my int) {
+	pinLabel := "Pin"
+	if coin.Pinned {
+		pinLabel = "Unpin"
+	}
+	// Actions look the coin up again as indices shift when coins move
+	index := func() int {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		return slices.Index(g.coinData, coin)
+	}
+
+	g.contextMenu = &ContextMenu{
+		Position: image.Pt(mx, my),
+		Items: []MenuItem{
+			{"Remove", func() { g.removeCoin(index()) }},
+			{pinLabel, func() { g.togglePin(index()) }},
+			{"Set Alert", func() {
+				if i := index(); i >= 0 {
+					g.mu.Lock()
+					g.SelectedCoinIndex = i
+					g.resetView()
+					g.mu.Unlock()
+					g.symbolInput.Focused = false
+					g.alertInput.Focused = true
+				}
+			}},
+			{"Export CSV", func() { g.exportCoinCSV(coin) }},
+			{"Copy price", func() { g.copyPrice(coin) }},
+		},
+	}
+	if slices.ContainsFunc(g.coinData, func(c *internal.CoinInfo) bool { return c.Delisted }) {
+		g.contextMenu.Items = append(g.contextMenu.Items, MenuItem{"Remove delisted", g.removeDelisted})
+	}
+}
+
+// handleContextMenuInput runs the clicked item or dismisses the menu on any
+// other click or Escape. The open menu takes all mouse input.
+func (g *Game) handleContextMenuInput() {
+	menu := g.contextMenu
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		g.contextMenu = nil
+		return
+	}
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+
+	g.contextMenu = nil
+	mx, my := ebiten.CursorPosition()
+	for i, item := range menu.Items {
+		if image.Pt(mx, my).In(g.itemRect(menu, i)) {
+			item.Action()
+			return
+		}
+	}
+}
+
+// drawContextMenu draws the open context menu, highlighting the hovered item.
+// Callers must hold g.mu.
+func (g *Game) drawContextMenu(screen *ebiten.Image) {
+	menu := g.contextMenu
+	if menu == nil {
+		return
+	}
+
+	// Keep the menu on screen
+	panel := g.itemRect(menu, 0).Union(g.itemRect(menu, len(menu.Items)-1))
+	bounds := screen.Bounds()
+	menu.Position.X -= max(panel.Max.X-bounds.Max.X, 0)
+	menu.Position.Y -= max(panel.Max.Y-bounds.Max.Y, 0)
+	panel = g.itemRect(menu, 0).Union(g.itemRect(menu, len(menu.Items)-1))
+
+	vector.DrawFilledRect(screen, float32(panel.Min.X), float32(panel.Min.Y), float32(panel.Dx()), float32(panel.Dy()), g.theme.Card, false)
+	vector.StrokeRect(screen, float32(panel.Min.X), float32(panel.Min.Y), float32(panel.Dx()), float32(panel.Dy()), 1, g.theme.Border, false)
+
+	mx, my := ebiten.CursorPosition()
+	for i, item := range menu.Items {
+		r := g.itemRect(menu, i)
+		if image.Pt(mx, my).In(r) {
+			vector.DrawFilledRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), g.theme.ControlActive, false)
+		}
+		esset.DrawText(screen, item.Label, 0, float64(r.Min.X+12), float64(r.Min.Y+6), g.fontFace, g.theme.TextPrimary)
+	}
+}
+
+// copyPrice copies coin's last price to the clipboard
+func (g *Game) copyPrice(coin *internal.CoinInfo) {
+	g.mu.Lock()
+	price := coin.LastPrice
+	if price == "" {
+		g.statusText = "No price yet"
+	}
+	g.mu.Unlock()
+	if price == "" {
+		return
+	}
+
+	go func() {
+		status := fmt.Sprintf("Copied %s", price)
+		if err := writeClipboard(price); err != nil {
+			log.Printf("Could not copy to clipboard: %v", err)
+			status = "Copy failed"
+		}
+		g.mu.Lock()
+		g.statusText = status
+		g.mu.Unlock()
+	}()
+}
+
+// exportCoinCSV writes coin's price history to a CSV file in the background
+func (g *Game) exportCoinCSV(coin *internal.CoinInfo) {
+	g.mu.Lock()
+	symbol, history := coin.Symbol, slices.Clone(coin.PriceHistory)
+	g.mu.Unlock()
+
+	go func() {
+		filename, err := exportCSV(symbol, history)
+		status := "Exported " + filename
+		if err != nil {
+			log.Printf("Could not export %s: %v", symbol, err)
+			status = "Export failed"
+		}
+		g.mu.Lock()
+		g.statusText = status
+		g.mu.Unlock()
+	}()
+}
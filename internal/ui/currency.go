@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"main/internal"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Quote asset the display currency rates are against. Only prices quoted in
+// it can be converted, coins in other quotes are always shown as-is.
+const baseQuote = "USDT"
+
+// Currencies offered by the Currency dropdown. Prices stay in their raw quote
+// (USDT) everywhere and are only multiplied by the rate when formatted.
+var currencies = []string{baseQuote, "EUR", "TRY"}
+
+// Binance pair giving each currency's rate against USDT, inverted when the
+// currency is the pair's base
+var currencyPairs = map[string]struct {
+	symbol string
+	invert bool
+}{
+	"EUR": {"EURUSDT", true},
+	"TRY": {"USDTTRY", false},
+}
+
+// How often the selected currency's rate is refetched
+const currencyRefreshInterval = time.Minute
+
+// The currency formatPrice converts to. It's read while drawing and written
+// by the rate refresh, so it has its own lock rather than g.mu.
+var display = struct {
+	sync.Mutex
+	currency string // "" while showing the raw quote
+	rate     float64
+}{rate: 1}
+
+func setDisplayCurrency(currency string, rate float64) {
+	display.Lock()
+	defer display.Unlock()
+	display.currency, display.rate = currency, rate
+}
+
+// displayCurrency returns the currency prices are shown in, "" for the raw
+// quote, and the rate formatPrice multiplies by
+func displayCurrency() (string, float64) {
+	display.Lock()
+	defer display.Unlock()
+	return display.currency, display.rate
+}
+
+// selectCurrency switches the display currency, fetching its rate right away
+func (g *Game) selectCurrency(currency string) {
+	g.mu.Lock()
+	g.currency = currency
+	g.mu.Unlock()
+
+	select {
+	case g.currencyChanged <- struct{}{}:
+	default: // a refresh is already pending
+	}
+}
+
+// runCurrencyRates keeps the selected currency's rate fresh until shutdown
+func (g *Game) runCurrencyRates() {
+	ticker := time.NewTicker(currencyRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-g.currencyChanged:
+		}
+		g.refreshCurrencyRate()
+	}
+}
+
+// refreshCurrencyRate fetches the selected currency's rate, falling back to
+// the raw quote when it can't be had
+func (g *Game) refreshCurrencyRate() {
+	g.mu.Lock()
+	currency := g.currency
+	g.mu.Unlock()
+
+	pair, ok := currencyPairs[currency]
+	if !ok {
+		setDisplayCurrency("", 1)
+		g.setCurrencyRateMissing(false)
+		return
+	}
+
+	priceStr, err := g.source.GetPrice(g.ctx, pair.symbol)
+	if g.ctx.Err() != nil {
+		return
+	}
+	var rate float64
+	if err == nil {
+		rate, err = strconv.ParseFloat(priceStr, 64)
+	}
+	if err == nil && rate <= 0 {
+		err = fmt.Errorf("invalid rate %s", priceStr)
+	}
+	if err != nil {
+		log.Printf("Could not get %s rate, showing USDT: %v", currency, err)
+		setDisplayCurrency("", 1)
+		g.setCurrencyRateMissing(true)
+		return
+	}
+
+	if pair.invert {
+		rate = 1 / rate
+	}
+	setDisplayCurrency(currency, rate)
+	g.setCurrencyRateMissing(false)
+}
+
+func (g *Game) setCurrencyRateMissing(missing bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.currencyRateMissing = missing
+}
+
+// currencyLabel names the currency prices are shown in, noting when the
+// selected one had to fall back to USDT. Callers must hold g.mu.
+func (g *Game) currencyLabel() string {
+	if g.currencyRateMissing {
+		return baseQuote + ", no " + g.currency + " rate"
+	}
+	if currency, _ := displayCurrency(); currency != "" {
+		return currency
+	}
+	return baseQuote
+}
+
+// priceUnit names what coin's prices are shown in: the display currency for
+// coins quoted in baseQuote, their own quote asset otherwise. Callers must
+// hold g.mu.
+func (g *Game) priceUnit(coin *internal.CoinInfo) string {
+	quote := g.quoteAsset(coin)
+	if quote == baseQuote || quote == "" {
+		return g.currencyLabel()
+	}
+	return quote
+}
+
+// formatQuoted is formatPrice for a price in quote, left unconverted unless
+// quote is baseQuote since the display rates don't apply to other quotes. An
+// unknown (empty) quote is assumed to be baseQuote.
+func formatQuoted(value float64, decimals int, quote string) string {
+	if quote != baseQuote && quote != "" {
+		return formatNumber(value, decimals)
+	}
+	return formatPrice(value, decimals)
+}
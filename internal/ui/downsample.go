@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"main/internal"
+	"math"
+)
+
+// Fewest points max_plot_points may be set to
+const minPlotPoints = 100
+
+// Narrowest candle, in logical pixels, before candles get merged
+const minCandleWidth = 3.0
+
+// Most points per pixel that still get point markers
+const maxMarkerDensity = 0.2
+
+// downsampleLTTB reduces points to threshold points with the
+// Largest-Triangle-Three-Buckets algorithm, which keeps the first and last
+// point and, from each bucket in between, the one forming the largest
+// triangle with its neighbours, so spikes survive. points is returned as is
+// when it's already short enough or threshold is below 3.
+func downsampleLTTB(points []internal.PricePoint, threshold int) []internal.PricePoint {
+	if threshold < 3 || len(points) <= threshold {
+		return points
+	}
+
+	sampled := make([]internal.PricePoint, 0, threshold)
+	sampled = append(sampled, points[0])
+
+	// Every point but the endpoints goes into one of threshold-2 buckets
+	bucketSize := float64(len(points)-2) / float64(threshold-2)
+	prev := 0
+	for b := range threshold - 2 {
+		start := int(float64(b)*bucketSize) + 1
+		end := int(float64(b+1)*bucketSize) + 1
+
+		// Average of the next bucket, the triangle's third corner
+		nextStart, nextEnd := end, min(int(float64(b+2)*bucketSize)+1, len(points))
+		var avgX, avgY float64
+		for i := nextStart; i < nextEnd; i++ {
+			avgX += float64(i)
+			avgY += points[i].Price
+		}
+		n := float64(nextEnd - nextStart)
+		avgX /= n
+		avgY /= n
+
+		best, bestArea := start, -1.0
+		prevX, prevY := float64(prev), points[prev].Price
+		for i := start; i < end; i++ {
+			area := math.Abs((prevX-avgX)*(points[i].Price-prevY) - (prevX-float64(i))*(avgY-prevY))
+			if area > bestArea {
+				best, bestArea = i, area
+			}
+		}
+		sampled = append(sampled, points[best])
+		prev = best
+	}
+
+	return append(sampled, points[len(points)-1])
+}
+
+// pointPrices returns the prices of points
+func pointPrices(points []internal.PricePoint) []float64 {
+	prices := make([]float64, len(points))
+	for i, pp := range points {
+		prices[i] = pp.Price
+	}
+	return prices
+}
+
+// plotPoints is how many points a line chart in area is downsampled to: one
+// per pixel column, up to max_plot_points
+func (g *Game) plotPoints(area chartRect) int {
+	return max(min(g.config.MaxPlotPoints, int(area.width)), 3)
+}
+
+// mergeKlines combines runs of consecutive klines so at most n are left,
+// each spanning the open of its first kline to the close of its last
+func mergeKlines(klines []internal.Kline, n int) []internal.Kline {
+	if n <= 0 || len(klines) <= n {
+		return klines
+	}
+	perCandle := (len(klines) + n - 1) / n
+	merged := make([]internal.Kline, 0, n)
+	for start := 0; start < len(klines); start += perCandle {
+		run := klines[start:min(start+perCandle, len(klines))]
+		k := run[0]
+		for _, next := range run[1:] {
+			k.High = math.Max(k.High, next.High)
+			k.Low = math.Min(k.Low, next.Low)
+			k.Close = next.Close
+			k.Volume += next.Volume
+		}
+		merged = append(merged, k)
+	}
+	return merged
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"main/internal"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDownsampleLTTB(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	points := make([]internal.PricePoint, 5000)
+	for i := range points {
+		points[i] = internal.PricePoint{Price: 100 + 10*math.Sin(float64(i)/50), Timestamp: start.Add(time.Duration(i) * time.Second)}
+	}
+	// A one-point spike LTTB should keep
+	points[2500].Price = 500
+
+	for _, threshold := range []int{3, 100, 700} {
+		sampled := downsampleLTTB(points, threshold)
+		if len(sampled) != threshold {
+			t.Errorf("threshold %d: got %d points", threshold, len(sampled))
+			continue
+		}
+		if sampled[0] != points[0] || sampled[len(sampled)-1] != points[len(points)-1] {
+			t.Errorf("threshold %d: endpoints %v, %v, want %v, %v", threshold, sampled[0], sampled[len(sampled)-1], points[0], points[len(points)-1])
+		}
+		spike := false
+		for i, p := range sampled {
+			spike = spike || p.Price == 500
+			if i > 0 && !p.Timestamp.After(sampled[i-1].Timestamp) {
+				t.Errorf("threshold %d: point %d out of order", threshold, i)
+			}
+		}
+		if !spike {
+			t.Errorf("threshold %d: the spike was dropped", threshold)
+		}
+	}
+}
+
+func TestDownsampleLTTBShortInput(t *testing.T) {
+	points := make([]internal.PricePoint, 50)
+	if got := downsampleLTTB(points, 100); len(got) != len(points) {
+		t.Errorf("got %d points, want the %d given back", len(got), len(points))
+	}
+	if got := downsampleLTTB(points, 2); len(got) != len(points) {
+		t.Errorf("threshold 2: got %d points, want the %d given back", len(got), len(points))
+	}
+}
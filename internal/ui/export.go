@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"encoding/csv"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"main/internal"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// exportCSV writes history to <symbol>_<timestamp>.csv with a
+// timestamp,symbol,price header, returning the file name. Prices are written
+// raw, in the quote asset with a '.' decimal point and no grouping, whatever
+// the number_format and display currency, so spreadsheets and -replay can
+// parse them.
+func exportCSV(symbol string, history []internal.PricePoint) (string, error) {
+	filename := fmt.Sprintf("%s_%s.csv", symbol, time.Now().Format("20060102-150405"))
+	file, err := os.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"timestamp", "symbol", "price"})
+	for _, pp := range history {
+		w.Write([]string{pp.Timestamp.Format(time.RFC3339Nano), symbol, strconv.FormatFloat(pp.Price, 'f', -1, 64)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	return filename, nil
+}
+
+// exportPNG encodes img to <symbol>_<timestamp>.png, returning the file name
+func exportPNG(symbol string, img image.Image) (string, error) {
+	filename := fmt.Sprintf("%s_%s.png", symbol, time.Now().Format("20060102-150405"))
+	file, err := os.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create PNG file: %w", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return "", fmt.Errorf("failed to write PNG file: %w", err)
+	}
+	return filename, nil
+}
+
+// captureChart copies the chart side of screen, right of the coin list, to an
+// image and saves it as a PNG in the background. Called from Draw before the
+// dropdowns and menus are drawn over it. Callers must hold g.mu.
+func (g *Game) captureChart(screen *ebiten.Image) {
+	bounds := screen.Bounds()
+	bounds.Min = image.Pt(int(coinListBaseWidth*g.deviceScale), int(g.topbarHeight))
+	if bounds.Empty() {
+		return
+	}
+
+	// Read back through an offscreen copy so the capture is independent of
+	// whatever gets drawn to the screen afterwards
+	shot := ebiten.NewImage(bounds.Dx(), bounds.Dy())
+	defer shot.Deallocate()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(-bounds.Min.X), float64(-bounds.Min.Y))
+	shot.DrawImage(screen, op)
+	img := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	shot.ReadPixels(img.Pix)
+
+	symbol := "chart"
+	if g.SelectedCoinIndex >= 0 && g.SelectedCoinIndex < len(g.coinData) {
+		symbol = g.coinData[g.SelectedCoinIndex].Symbol
+	}
+	go func() {
+		filename, err := exportPNG(symbol, img)
+		status := "Saved " + filename
+		if err != nil {
+			log.Printf("Could not save chart image: %v", err)
+			status = "Screenshot failed"
+		}
+		g.mu.Lock()
+		g.statusText = status
+		g.mu.Unlock()
+	}()
+}
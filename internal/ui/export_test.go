@@ -0,0 +1,46 @@
+//go:build !js
+
+package ui
+
+import (
+	"encoding/csv"
+	"main/internal"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExportCSVIgnoresNumberFormat(t *testing.T) {
+	t.Chdir(t.TempDir())
+	setNumberFormat(numberFormatDot)
+	t.Cleanup(func() { setNumberFormat(numberFormatComma) })
+
+	history := []internal.PricePoint{
+		{Price: 1234567.89, Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Price: 0.5, Timestamp: time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC)},
+	}
+	filename, err := exportCSV("BTCUSDT", history)
+	if err != nil {
+		t.Fatalf("exportCSV: %v", err)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("reading the CSV back: %v", err)
+	}
+
+	want := []string{"price", "1234567.89", "0.5"}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(want))
+	}
+	for i, row := range rows {
+		if row[2] != want[i] {
+			t.Errorf("row %d price = %q, want %q", i, row[2], want[i])
+		}
+	}
+}
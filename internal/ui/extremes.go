@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/esset/v2"
+)
+
+// Dash and gap lengths, in logical pixels, of the reference lines
+const (
+	dashLength = 6.0
+	dashGap    = 4.0
+)
+
+// strokeDashedLine draws a horizontal dashed line at y from left to right
+func (g *Game) strokeDashedLine(screen *ebiten.Image, left, right, y float64, clr color.Color) {
+	dash, gap := dashLength*g.deviceScale, dashGap*g.deviceScale
+	for x := left; x < right; x += dash + gap {
+		end := min(x+dash, right)
+		vector.StrokeLine(screen, float32(x), float32(y), float32(end), float32(y), float32(g.deviceScale), clr, false)
+	}
+}
+
+// drawReferenceLine draws a labelled dashed line at price, skipping prices
+// outside minPrice..maxPrice. The label sits above the line at the right
+// edge of the chart, or below it when that would leave the chart.
+func (g *Game) drawReferenceLine(screen *ebiten.Image, area chartRect, label string, price, minPrice, maxPrice float64, clr color.RGBA) {
+	if price < minPrice || price > maxPrice {
+		return
+	}
+	y := area.top + area.height - g.priceToY(price, minPrice, maxPrice)*area.height
+	g.strokeDashedLine(screen, area.left, area.left+area.width, y, clr)
+
+	tag := label + " " + g.formatSelectedPrice(price)
+	w, h := text.Measure(tag, g.fontFace, 0)
+	ty := y - h - 2
+	if ty < area.top {
+		ty = y + 2
+	}
+	esset.DrawText(screen, tag, 0, area.left+area.width-w-4, ty, g.fontFace, clr)
+}
+
+// drawHighLow marks the highest and lowest price in the chart window, and
+// with showExtremes the selected coin's session extremes as well. high and
+// low are the window's own extremes, before any widening for overlays.
+// Callers must hold g.mu.
+func (g *Game) drawHighLow(screen *ebiten.Image, area chartRect, high, low, minPrice, maxPrice float64) {
+	g.drawReferenceLine(screen, area, "H", high, minPrice, maxPrice, g.theme.TextMuted)
+	g.drawReferenceLine(screen, area, "L", low, minPrice, maxPrice, g.theme.TextMuted)
+
+	if !g.showExtremes || g.SelectedCoinIndex < 0 || g.SelectedCoinIndex >= len(g.coinData) {
+		return
+	}
+	coin := g.coinData[g.SelectedCoinIndex]
+	// Session extremes that equal the window's would just overdraw its lines
+	if coin.SessionHigh > 0 && coin.SessionHigh != high {
+		g.drawReferenceLine(screen, area, "Session H", coin.SessionHigh, minPrice, maxPrice, g.theme.Accent)
+	}
+	if coin.SessionLow > 0 && coin.SessionLow != low {
+		g.drawReferenceLine(screen, area, "Session L", coin.SessionLow, minPrice, maxPrice, g.theme.Accent)
+	}
+}
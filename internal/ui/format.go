@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Separators used by formatPrice, set from the number_format config
+var thousandsSep = ","
+var decimalSep = "."
+
+// Number format styles for the number_format config
+const (
+	numberFormatComma = "1,234.56"
+	numberFormatDot   = "1.234,56"
+)
+
+func setNumberFormat(style string) {
+	if style == numberFormatDot {
+		thousandsSep, decimalSep = ".", ","
+	} else {
+		thousandsSep, decimalSep = ",", "."
+	}
+}
+
+// formatPrice formats value, converted to the display currency, with
+// formatNumber
+func formatPrice(value float64, decimals int) string {
+	_, rate := displayCurrency()
+	return formatNumber(value*rate, decimals)
+}
+
+// formatNumber formats value with decimals digits after the decimal separator
+// and the integer part grouped in thousands, e.g. 67,000.123
+func formatNumber(value float64, decimals int) string {
+	digits := strconv.FormatFloat(value, 'f', max(decimals, 0), 64)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	intPart, fraction, _ := strings.Cut(digits, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(thousandsSep)
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString(decimalSep)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
@@ -0,0 +1,3766 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"main/internal"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/esset/v2"
+)
+
+// Glyphs of the static UI text, cached at startup along with the tracked symbols
+const glyphsToPreload = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789.,:/* ETHUSDTBTCBNBXP▲▼–%+-()_"
+const baseFontSize = 4
+
+// Width of the coin list column left of the chart, before device scaling
+const coinListBaseWidth = 180
+
+// Space above a coin list row's text that still belongs to the row, before
+// device scaling
+const coinRowInset = 3
+
+// An open dropdown shows at most this many options, and scrolls the rest
+const maxDropdownRows = 12
+
+// Sparklines in the coin list cover this many of the latest points
+const sparklinePoints = 30
+
+// Width of each coin list sparkline, before device scaling
+const sparklineBaseWidth = 40
+
+// How long the topbar price flashes after it moves
+const tickFlashDuration = 600 * time.Millisecond
+
+// Width of the pin toggle area at the right edge of each Crypto dropdown row,
+// before device scaling
+const pinZoneBaseWidth = 18
+
+const stateFilename = "crypto_app_state.json"
+
+// Most points a coin's history keeps before dropping the oldest
+const maxHistoryPoints = 10000
+
+// How long cached klines are used before being refetched
+const klineRefreshInterval = 30 * time.Second
+
+// After this many polling rounds in a row where every fetch failed, polling
+// backs off, doubling the interval each further failed round up to maxPollBackoff
+const (
+	pollFailureThreshold = 3
+	maxPollBackoff       = time.Minute
+)
+
+// A coin whose latest point is older than this many update intervals is stale
+const staleIntervals = 3
+
+// How often a delisted coin's price is fetched, in case it trades again
+const delistedRecheckInterval = 10 * time.Minute
+
+// Smallest number of points the chart can be zoomed in to
+const minViewPoints = 10
+
+// Two clicks on the chart within this interval reset pan/zoom
+const doubleClickInterval = 300 * time.Millisecond
+
+// Period, in points, of the RSI pane
+const defaultRSIPeriod = 14
+
+// Fast, slow and signal EMA periods, in points, of the MACD pane
+const (
+	macdFast   = 12
+	macdSlow   = 26
+	macdSignal = 9
+)
+
+// Period, in points, and width in standard deviations of the Bollinger Bands
+const (
+	bollingerPeriod = 20
+	bollingerMult   = 2.0
+)
+
+// Moving average periods, in points, for each MA dropdown option (0 is off)
+var maPeriods = []int{0, 7, 25, 99}
+
+// EMA smoothing factors for each Smooth dropdown option (0 is the raw line).
+// Lower values smooth more but lag further behind the price.
+var smoothingAlphas = []float64{0, 0.3, 0.1}
+
+// Span of history shown for each timeline option
+var timelineDurations = map[string]time.Duration{
+	"1h": time.Hour,
+	"4h": 4 * time.Hour,
+	"1d": 24 * time.Hour,
+	"1w": 7 * 24 * time.Hour,
+}
+
+// Kline interval and count requested for each timeline option
+var klineParams = map[string]struct {
+	interval string
+	limit    int
+	bucket   time.Duration // interval as a duration, for aggregateOHLC
+}{
+	"1h": {"1m", 60, time.Minute},
+	"4h": {"5m", 48, 5 * time.Minute},
+	"1d": {"15m", 96, 15 * time.Minute},
+	"1w": {"2h", 84, 2 * time.Hour},
+}
+
+// Most klines Binance returns for one request
+const maxKlineLimit = 1000
+
+type AppData struct {
+	Version  int                  `json:"version"` // stateVersion when saved, 0 for files from before versioning
+	Symbols  []string             `json:"symbols"` // tracked symbols in display order, the source of truth on load
+	CoinData []*internal.CoinInfo `json:"coin_data"`
+	Alerts   []*Alert             `json:"alerts"`
+	Window   *WindowState         `json:"window,omitempty"` // nil until the first save
+}
+
+type Game struct {
+	coinData             []*internal.CoinInfo
+	lastUpdateTime       time.Time
+	lastSuccessfulUpdate time.Time                      // last time any coin got a price
+	dirty                bool                           // state changed since the last save
+	headless             bool                           // no window, see RunHeadless
+	symbolInfo           map[string]internal.SymbolInfo // exchange info by symbol, nil until loaded
+	savedWindow          *WindowState                   // window geometry loaded from state, kept as-is when headless
+	fullWindow           *WindowState                   // window size to restore when leaving compact mode
+	saveMu               sync.Mutex                     // serializes autosave and shutdown saves
+	mu                   sync.Mutex
+	wg                   sync.WaitGroup
+	source               internal.PriceSource
+	noSave               bool            // replaying, leave the state file alone
+	ctx                  context.Context // cancelled on shutdown to abort in-flight fetches
+	pollDone             chan struct{}   // closed once runPolling has returned, nil when it isn't running
+	serverDone           chan struct{}   // closed once the metrics server has stopped, nil when it isn't running
+	failStreak           int             // polling rounds in a row without a single price
+	nextPoll             time.Time       // when runPolling fetches next, for the reconnect countdown
+	lastPoll             time.Time       // when the last polling round finished
+	paused               bool            // price updates are frozen, see togglePause
+	showPerf             bool            // F3 debug overlay, see perf.go
+	showHelp             bool            // F1 shortcut overlay, see shortcuts.go
+	capturePending       bool            // F12 was pressed, the next Draw saves the chart as a PNG
+	alertPlayer          *audio.Player   // alert beep, nil without audio, see sound.go
+	lastAlertSound       time.Time
+	perf                 perfStats
+	metrics              *metrics
+	currency             string        // selected display currency, see currency.go
+	currencyRateMissing  bool          // the selected currency's rate couldn't be fetched
+	currencyChanged      chan struct{} // wakes runCurrencyRates after a selection
+	cancel               context.CancelFunc
+	stream               *internal.PriceStream
+	streamCancel         context.CancelFunc
+	lastTickerUpdate     time.Time
+	tickersRefreshing    atomic.Bool
+	book                 *bookQuote // selected coin's order book top, nil until fetched
+	fontFace             text.Face
+	physicalLineHeight   float64
+	deviceScale          float64
+	SelectedCoinIndex    int
+	CompareCoinIndex     int // coin overlaid on the line chart, -1 when not comparing
+	solidColorImage      *ebiten.Image
+	fontData             []byte        // TTF data the faces are built from
+	cachedGlyphs         map[rune]bool // runes already drawn once by cacheGlyphs
+	pendingGlyphs        []string      // strings to cache on the next Update, guarded by g.mu
+	topbarStale          bool          // dropdown options changed since layoutTopbar, guarded by g.mu
+	theme                Theme
+	config               Config
+
+	// Topbar fields
+	topbarHeight   float64
+	dropdowns      []*Dropdown
+	activeDropdown *Dropdown
+	symbolInput    *TextInput
+	addButton      image.Rectangle
+	statusText     string // result of the last add/remove/alert action, shown after the inputs
+	alertInput     *TextInput
+	holdingsInput  *TextInput      // quantity held of the selected coin
+	themeButton    image.Rectangle // laid out each frame at the right edge
+	settingsButton image.Rectangle // gear left of the theme button, laid out each frame
+	pauseButton    image.Rectangle // left of the gear, laid out each frame
+	settings       *SettingsPanel
+	contextMenu    *ContextMenu // open coin row menu, nil when closed
+	readout        string       // hovered chart value for the topbar, set by the chart each frame
+	rowDrag        *rowDrag     // coin row held down in the list, nil when none
+	chartType      string       // "line" or "candle"
+	timeline       string       // "1h", "4h", "1d", "1w"
+	maPeriod       int          // moving average overlay period in points, 0 when off
+	smoothAlpha    float64      // EMA smoothing factor of the price line, 0 for the raw line
+	showExtremes   bool         // mark the selected coin's session high and low on the chart
+	yRangeMode     string       // yRangeFit or yRangeLocked
+	lockedSymbol   string       // coin the price axis was locked on
+	lockedMin      float64      // locked price axis range
+	lockedMax      float64
+	shownMin       float64 // price axis range last drawn, what locking keeps
+	shownMax       float64
+	logScale       bool // plot prices on a log10 axis
+	gridView       bool // tile every coin's chart instead of the selected one
+	rsiPeriod      int  // RSI pane period in points, 0 when hidden
+	showBollinger  bool // draw Bollinger Bands over the line chart
+	showMACD       bool // MACD pane below the chart
+
+	// Pan/zoom window over visiblePoints as fractions of it, set by the wheel,
+	// dragging and the overview brush. brushEnd is 0 when showing the full range.
+	brushStart     float64
+	brushEnd       float64
+	brushDrag      brushPart // part of the overview brush being dragged
+	brushGrab      float64   // cursor position the brush drag started from, see brush.go
+	chartArea      chartRect // where the line chart was last drawn, for hit-testing
+	overviewArea   chartRect // overview strip under the line chart, zero when hidden
+	dragging       bool
+	dragLastX      int
+	dragRemainder  float64 // fractional points of drag not yet applied
+	lastChartClick time.Time
+
+	klineCache  map[string]*klineEntry // keyed by symbol@timeline
+	klineKey    string                 // cache key of the klines last shown
+	klineCancel context.CancelFunc     // cancels the kline fetch for klineKey
+
+	alerts      []*Alert
+	banner      string // on-screen alert banner
+	bannerUntil time.Time
+
+	// Big number mode fields
+	bigNumberMode   bool
+	bigFontFace     text.Face
+	bigFontFaceSize image.Point // screen size the big font was built for
+}
+
+type Dropdown struct {
+	ID       string
+	Label    string
+	Options  []string
+	IsOpen   bool
+	Bounds   image.Rectangle
+	Selected int
+	OnSelect func(int)
+
+	Filter       string // typed while open, narrows Options by case-insensitive substring
+	Highlight    int    // keyboard highlight, an index into visibleOptions
+	ScrollOffset int    // first visibleOptions row shown when there are over maxDropdownRows
+}
+
+// visibleOptions returns the indices into Options that match Filter
+func (d *Dropdown) visibleOptions() []int {
+	filter := strings.ToLower(d.Filter)
+	visible := make([]int, 0, len(d.Options))
+	for i, option := range d.Options {
+		// "btcusdt" still finds "BTC/USDT"
+		option = strings.ToLower(option)
+		if strings.Contains(option, filter) || strings.Contains(strings.ReplaceAll(option, "/", ""), filter) {
+			visible = append(visible, i)
+		}
+	}
+	return visible
+}
+
+func (d *Dropdown) open() {
+	d.IsOpen = true
+	d.Filter = ""
+	d.Highlight = max(d.Selected, 0)
+	d.ScrollOffset = 0
+	d.scrollToHighlight()
+}
+
+func (d *Dropdown) close() {
+	d.IsOpen = false
+	d.Filter = ""
+	d.Highlight = 0
+	d.ScrollOffset = 0
+}
+
+// scroll moves the option list by rows, keeping it within the options
+func (d *Dropdown) scroll(rows int) {
+	d.ScrollOffset = max(0, min(d.ScrollOffset+rows, len(d.visibleOptions())-maxDropdownRows))
+}
+
+// scrollToHighlight scrolls the option list just enough to show the
+// highlighted row
+func (d *Dropdown) scrollToHighlight() {
+	if d.Highlight < d.ScrollOffset {
+		d.ScrollOffset = d.Highlight
+	} else if d.Highlight >= d.ScrollOffset+maxDropdownRows {
+		d.ScrollOffset = d.Highlight - maxDropdownRows + 1
+	}
+	d.scroll(0)
+}
+
+type klineEntry struct {
+	klines    []internal.Kline
+	fetchedAt time.Time
+	loading   bool
+	err       error // error of the last fetch, if any
+}
+
+// chartRect is the plotting area of the chart in screen pixels
+type chartRect struct {
+	left, top, width, height float64
+}
+
+// overlaps reports whether r and o share any area
+func (r chartRect) overlaps(o chartRect) bool {
+	return r.left < o.left+o.width && o.left < r.left+r.width &&
+		r.top < o.top+o.height && o.top < r.top+r.height
+}
+
+type TextInput struct {
+	Bounds      image.Rectangle
+	Text        string
+	Placeholder string
+	Focused     bool
+	MaxLen      int
+	Filter      func(rune) rune // maps typed runes, returning 0 to reject them
+}
+
+// Update feeds typed characters into the input while focused and reports
+// whether Enter was pressed with some text entered
+func (t *TextInput) Update() bool {
+	if !t.Focused {
+		return false
+	}
+
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if len(t.Text) >= t.MaxLen {
+			break
+		}
+		if t.Filter != nil {
+			r = t.Filter(r)
+		}
+		if r != 0 {
+			t.Text += string(r)
+		}
+	}
+
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)
+	if ctrl && inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		t.paste()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(t.Text) > 0 {
+		t.Text = t.Text[:len(t.Text)-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		t.Focused = false
+	}
+	return inpututil.IsKeyJustPressed(ebiten.KeyEnter) && t.Text != ""
+}
+
+// paste appends the clipboard text, trimmed of surrounding whitespace and
+// newlines. It's all or nothing: text the filter rejects any rune of, or that
+// would overflow MaxLen, is ignored.
+func (t *TextInput) paste() {
+	clip, err := readClipboard()
+	if err != nil {
+		log.Printf("Could not read clipboard: %v", err)
+		return
+	}
+
+	var pasted strings.Builder
+	for _, r := range strings.TrimSpace(clip) {
+		if t.Filter != nil {
+			r = t.Filter(r)
+		}
+		if r == 0 {
+			log.Printf("Ignoring paste of %q", clip)
+			return
+		}
+		pasted.WriteRune(r)
+	}
+	if len(t.Text)+pasted.Len() > t.MaxLen {
+		log.Printf("Ignoring paste of %q, too long", clip)
+		return
+	}
+	t.Text += pasted.String()
+}
+
+// symbolFilter accepts letters and digits, uppercasing letters
+func symbolFilter(r rune) rune {
+	if r >= 'a' && r <= 'z' {
+		return r - 'a' + 'A'
+	}
+	if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+		return r
+	}
+	return 0
+}
+
+// priceFilter accepts digits and a decimal point
+func priceFilter(r rune) rune {
+	if (r >= '0' && r <= '9') || r == '.' {
+		return r
+	}
+	return 0
+}
+
+func (g *Game) initSolidColorImage() {
+	if g.solidColorImage == nil {
+		g.solidColorImage = ebiten.NewImage(1, 1)
+		g.solidColorImage.Fill(color.White)
+	}
+}
+
+func (g *Game) updateSingleCoin(coin *internal.CoinInfo) {
+	defer g.wg.Done()
+	g.fetchCoin(coin)
+}
+
+// fetchCoin fetches and applies coin's price on its own
+func (g *Game) fetchCoin(coin *internal.CoinInfo) {
+	// Show a spinner while a coin that failed last time is retried
+	g.mu.Lock()
+	if coin.FetchError != nil {
+		coin.IsLoading = true
+	}
+	g.mu.Unlock()
+
+	start := time.Now()
+	newPriceStr, err := g.source.GetPrice(g.ctx, coin.Symbol)
+	if g.ctx.Err() != nil {
+		return
+	}
+	g.metrics.recordFetch(coin.Symbol, time.Since(start), err)
+	g.applyPrice(coin, newPriceStr, err, true)
+}
+
+// applyPrice stores the result of a price fetch on coin, adding a history
+// point when recordHistory is set
+func (g *Game) applyPrice(coin *internal.CoinInfo, newPriceStr string, err error, recordHistory bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	coin.IsLoading = false
+	if errors.Is(err, internal.ErrInvalidSymbol) && !coin.Delisted {
+		log.Printf("%s is no longer trading, checking again every %s", coin.Symbol, delistedRecheckInterval)
+		coin.Delisted = true
+		coin.CheckedAt = time.Now()
+		g.dirty = true
+	}
+	if err != nil {
+		// Once polling backs off the failures are logged once per round instead
+		if g.failStreak < pollFailureThreshold {
+			log.Printf("Could not get price [%s]: %v", coin.Symbol, err)
+		}
+		coin.FetchError = err
+		coin.DisplayStr = fmt.Sprintf("%s: Error", coin.Symbol)
+		return
+	}
+
+	newPriceFloat, parseErr := strconv.ParseFloat(newPriceStr, 64)
+
+	if parseErr != nil {
+		log.Printf("Could not parse new price [%s]: %v, Price: %s", coin.Symbol, parseErr, newPriceStr)
+		coin.FetchError = parseErr
+		coin.DisplayStr = fmt.Sprintf("%s: Parse Error", coin.Symbol)
+		return
+	}
+
+	if old, err := strconv.ParseFloat(coin.LastPrice, 64); err == nil && old != newPriceFloat {
+		coin.ChangedAt = time.Now()
+		coin.ChangeDir = 1
+		if newPriceFloat < old {
+			coin.ChangeDir = -1
+		}
+	}
+	if newPriceFloat > coin.SessionHigh {
+		coin.SessionHigh = newPriceFloat
+	}
+	if coin.SessionLow == 0 || newPriceFloat < coin.SessionLow {
+		coin.SessionLow = newPriceFloat
+	}
+	if coin.Delisted && g.trading(coin.Symbol) {
+		log.Printf("%s is trading again", coin.Symbol)
+		coin.Delisted = false
+		g.dirty = true
+	}
+	coin.PreviousPrice = coin.LastPrice
+	coin.LastPrice = newPriceStr
+	coin.FetchError = nil
+	g.lastSuccessfulUpdate = time.Now()
+	if g.failStreak >= pollFailureThreshold {
+		log.Printf("Price fetches recovered after %d failed rounds", g.failStreak)
+	}
+	g.failStreak = 0
+	g.dirty = true
+
+	coin.DisplayStr = fmt.Sprintf("%s: %s", coin.Symbol, formatQuoted(newPriceFloat, coin.Decimals(), g.quoteAsset(coin)))
+
+	if recordHistory {
+		coin.PriceHistory = appendHistory(coin.PriceHistory, internal.PricePoint{Price: newPriceFloat, Timestamp: time.Now()})
+	}
+
+	g.checkAlerts(coin.Symbol, newPriceFloat)
+}
+
+// appendHistory appends point and drops the oldest points beyond
+// maxHistoryPoints. Reslicing from the front lets append reuse the backing
+// array, so it only reallocates once the spare capacity is used up.
+func appendHistory(history []internal.PricePoint, point internal.PricePoint) []internal.PricePoint {
+	if len(history) >= maxHistoryPoints {
+		history = history[len(history)-maxHistoryPoints+1:]
+	}
+	return append(history, point)
+}
+
+// updateAllPrices fetches every coin's price, reporting whether any of them
+// succeeded
+func (g *Game) updateAllPrices() bool {
+	coins, recheck := g.pollCoins()
+	// Delisted coins are fetched on their own so they can't fail the batch
+	for _, coin := range recheck {
+		g.wg.Add(1)
+		go g.updateSingleCoin(coin)
+	}
+	if len(coins) == 0 {
+		g.wg.Wait()
+		return true
+	}
+
+	g.mu.Lock()
+	lastSuccess := g.lastSuccessfulUpdate
+	g.mu.Unlock()
+
+	symbols := make([]string, len(coins))
+	for i, coin := range coins {
+		symbols[i] = coin.Symbol
+	}
+
+	start := time.Now()
+	defer func() { g.perf.lastFetch.Store(int64(time.Since(start))) }()
+
+	prices, err := g.source.GetPrices(g.ctx, symbols)
+	if g.ctx.Err() != nil {
+		return true
+	}
+	// Symbols the batch covered count as fetched in its time, the rest are
+	// recorded by updateSingleCoin
+	elapsed := time.Since(start)
+	for symbol := range prices {
+		g.metrics.recordFetch(symbol, elapsed, nil)
+	}
+	// Per-symbol requests would only run into the same limit
+	if errors.Is(err, internal.ErrRateLimited) {
+		log.Printf("Batch price request rate limited, skipping this round: %v", err)
+		g.wg.Wait()
+		return false
+	}
+	if err != nil {
+		log.Printf("Batch price request failed, falling back to per-symbol requests: %v", err)
+	}
+
+	for _, coin := range coins {
+		if price, ok := prices[coin.Symbol]; ok {
+			g.applyPrice(coin, price, nil, true)
+			continue
+		}
+		// Missing from the batch (or the batch failed), fetch it on its own
+		g.wg.Add(1)
+		go g.updateSingleCoin(coin)
+	}
+	g.wg.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.lastSuccessfulUpdate != lastSuccess
+}
+
+// updateTicker24h fetches the 24h statistics for coin. A failure keeps the
+// previous ticker so one bad response doesn't blank the row.
+func (g *Game) updateTicker24h(coin *internal.CoinInfo) {
+	ticker, err := g.source.GetTicker24h(g.ctx, coin.Symbol)
+	if errors.Is(err, internal.ErrUnsupported) || g.ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		log.Printf("Could not get 24h ticker [%s]: %v", coin.Symbol, err)
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	coin.Ticker24h = &ticker
+}
+
+// bookQuote is the best bid and ask of a coin's order book
+type bookQuote struct {
+	symbol   string
+	bid, ask float64
+}
+
+// updateBookTicker fetches the order book top of symbol for the spread
+// readout. Like the 24h ticker, a failure keeps the previous quote.
+func (g *Game) updateBookTicker(symbol string) {
+	bidStr, askStr, err := g.source.GetBookTicker(g.ctx, symbol)
+	if errors.Is(err, internal.ErrUnsupported) || g.ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		log.Printf("Could not get book ticker [%s]: %v", symbol, err)
+		return
+	}
+	bid, bidErr := strconv.ParseFloat(bidStr, 64)
+	ask, askErr := strconv.ParseFloat(askStr, 64)
+	if bidErr != nil || askErr != nil {
+		log.Printf("Could not parse book ticker [%s]: bid %s, ask %s", symbol, bidStr, askStr)
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.book = &bookQuote{symbol: symbol, bid: bid, ask: ask}
+}
+
+// spreadText describes the selected coin's bid/ask spread, e.g. "Spread 0.01
+// (0.002%)", or is empty without a quote for it. Callers must hold g.mu.
+func (g *Game) spreadText() string {
+	if g.book == nil || g.SelectedCoinIndex < 0 || g.SelectedCoinIndex >= len(g.coinData) ||
+		g.coinData[g.SelectedCoinIndex].Symbol != g.book.symbol {
+		return ""
+	}
+	spread := g.book.ask - g.book.bid
+	mid := (g.book.ask + g.book.bid) / 2
+	if mid <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("Spread %s (%.3f%%)", g.formatSelectedPrice(spread), spread/mid*100)
+}
+
+// toggleSpread turns the spread readout, and the order book requests behind
+// it, on or off and saves the choice
+func (g *Game) toggleSpread() {
+	g.config.ShowSpread = !g.config.ShowSpread
+	if err := saveConfig(g.config, configFilename); err != nil {
+		log.Printf("Error saving config: %v", err)
+	}
+}
+
+// fetchDecimals looks up the price precision of every coin in coins that
+// doesn't have one yet from the exchange's tick sizes
+func (g *Game) fetchDecimals(coins []*internal.CoinInfo) {
+	g.mu.Lock()
+	var symbols []string
+	for _, coin := range coins {
+		if coin.PriceDecimals == nil {
+			symbols = append(symbols, coin.Symbol)
+		}
+	}
+	g.mu.Unlock()
+	if len(symbols) == 0 {
+		return
+	}
+
+	infos, err := g.source.GetExchangeInfo(symbols...)
+	if errors.Is(err, internal.ErrUnsupported) {
+		return
+	}
+	if err != nil {
+		log.Printf("Could not get price precision: %v", err)
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, coin := range coins {
+		if info, ok := infos[coin.Symbol]; ok && info.PriceDecimals >= 0 {
+			decimals := info.PriceDecimals
+			coin.PriceDecimals = &decimals
+			g.dirty = true
+		}
+	}
+}
+
+// refreshTickers updates the 24h statistics of every coin in the background,
+// skipping the round if the previous one is still running
+func (g *Game) refreshTickers() {
+	if !g.tickersRefreshing.CompareAndSwap(false, true) {
+		return
+	}
+
+	// Delisted coins would only fail
+	g.mu.Lock()
+	var coins []*internal.CoinInfo
+	for _, coin := range g.coinData {
+		if !coin.Delisted {
+			coins = append(coins, coin)
+		}
+	}
+	// The order book is only shown for the selected coin, so it's the only
+	// one worth the request weight
+	bookSymbol := ""
+	if g.config.ShowSpread && g.SelectedCoinIndex >= 0 && g.SelectedCoinIndex < len(g.coinData) && !g.coinData[g.SelectedCoinIndex].Delisted {
+		bookSymbol = g.coinData[g.SelectedCoinIndex].Symbol
+	}
+	g.mu.Unlock()
+	go func() {
+		defer g.tickersRefreshing.Store(false)
+
+		var wg sync.WaitGroup
+		for _, coin := range coins {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				g.updateTicker24h(coin)
+			}()
+		}
+		if bookSymbol != "" {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				g.updateBookTicker(bookSymbol)
+			}()
+		}
+		wg.Wait()
+	}()
+}
+
+// consumeStream applies every pending streamed trade price. History is still
+// sampled once per UpdateInterval so it keeps the same density as polling
+func (g *Game) consumeStream(stream *internal.PriceStream) {
+	latest := drainStream(stream)
+
+	sampleDue := time.Since(g.lastUpdateTime) >= internal.UpdateInterval()
+	if sampleDue {
+		g.lastUpdateTime = time.Now()
+	}
+
+	for _, coin := range g.coinSnapshot() {
+		price, ok := latest[coin.Symbol]
+		if !ok {
+			// Quiet symbol, sample its last known price. Applying it again
+			// would make PreviousPrice equal LastPrice and flatten its direction.
+			if sampleDue {
+				g.sampleLastPrice(coin)
+			}
+			continue
+		}
+		g.applyPrice(coin, price, nil, sampleDue)
+	}
+}
+
+// sampleLastPrice adds a history point at coin's last known price, for
+// streamed symbols that had no trades since the last sample
+func (g *Game) sampleLastPrice(coin *internal.CoinInfo) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	price, err := strconv.ParseFloat(coin.LastPrice, 64)
+	if err != nil {
+		return
+	}
+	coin.PriceHistory = appendHistory(coin.PriceHistory, internal.PricePoint{Price: price, Timestamp: time.Now()})
+	g.dirty = true
+}
+
+// drainStream empties the stream's pending updates and returns the latest
+// price of each symbol among them
+func drainStream(stream *internal.PriceStream) map[string]string {
+	latest := make(map[string]string)
+	for {
+		select {
+		case update := <-stream.Updates:
+			latest[update.Symbol] = update.Price
+		default:
+			return latest
+		}
+	}
+}
+
+// togglePause freezes or resumes price updates. Rendering and input carry on
+// either way.
+func (g *Game) togglePause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.paused = !g.paused
+	if g.paused {
+		g.statusText = "Updates paused"
+		log.Printf("Price updates paused")
+	} else {
+		g.statusText = "Updates resumed"
+		log.Printf("Price updates resumed")
+	}
+}
+
+// dedupeSymbols drops case-insensitive duplicates from symbols, keeping the
+// first occurrence and the original order
+func dedupeSymbols(symbols []string) []string {
+	seen := make(map[string]bool, len(symbols))
+	result := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		key := strings.ToUpper(symbol)
+		if seen[key] {
+			log.Printf("Dropping duplicate symbol %s", symbol)
+			continue
+		}
+		seen[key] = true
+		result = append(result, symbol)
+	}
+	return result
+}
+
+// dedupeCoins drops coins whose symbol already appeared earlier in the list
+func dedupeCoins(coins []*internal.CoinInfo) []*internal.CoinInfo {
+	seen := make(map[string]bool, len(coins))
+	result := make([]*internal.CoinInfo, 0, len(coins))
+	for _, coin := range coins {
+		key := strings.ToUpper(coin.Symbol)
+		if seen[key] {
+			log.Printf("Dropping duplicate coin %s from loaded state", coin.Symbol)
+			continue
+		}
+		seen[key] = true
+		result = append(result, coin)
+	}
+	return result
+}
+
+// reconcileCoins builds the coin list for symbols, reusing saved coins where
+// they exist and dropping saved coins that are no longer tracked
+func reconcileCoins(symbols []string, saved []*internal.CoinInfo) []*internal.CoinInfo {
+	bySymbol := make(map[string]*internal.CoinInfo, len(saved))
+	for _, coin := range saved {
+		bySymbol[strings.ToUpper(coin.Symbol)] = coin
+	}
+
+	coins := make([]*internal.CoinInfo, 0, len(symbols))
+	reused := 0
+	for _, symbol := range symbols {
+		coin, ok := bySymbol[strings.ToUpper(symbol)]
+		if ok {
+			reused++
+		} else {
+			coin = &internal.CoinInfo{Symbol: symbol}
+		}
+		coins = append(coins, coin)
+	}
+	if dropped := len(saved) - reused; dropped > 0 {
+		log.Printf("Dropped %d saved coins that are no longer tracked", dropped)
+	}
+	return coins
+}
+
+// initCoinData builds the coin list from loaded state. A nil symbol list was
+// never saved and starts from TargetSymbols, while an empty one was saved
+// after removing every coin and stays empty.
+func initCoinData(loadedData AppData) []*internal.CoinInfo {
+	if loadedData.Symbols != nil {
+		log.Println("Reconciling coin data with saved symbol list.")
+		loadedData.CoinData = reconcileCoins(dedupeSymbols(loadedData.Symbols), dedupeCoins(loadedData.CoinData))
+	}
+
+	if loadedData.Symbols != nil || len(loadedData.CoinData) > 0 {
+		log.Println("Initializing coin data from loaded state.")
+		loadedData.CoinData = dedupeCoins(loadedData.CoinData)
+		for _, coin := range loadedData.CoinData {
+			if coin.PriceHistory == nil {
+				coin.PriceHistory = []internal.PricePoint{}
+			} else if len(coin.PriceHistory) > maxHistoryPoints {
+				coin.PriceHistory = coin.PriceHistory[len(coin.PriceHistory)-maxHistoryPoints:]
+			}
+			if coin.LastPrice != "" {
+				p, err := strconv.ParseFloat(coin.LastPrice, 64)
+				if err == nil {
+					_, quote, _ := internal.SplitSymbol(coin.Symbol)
+					coin.DisplayStr = fmt.Sprintf("%s: %s", coin.Symbol, formatQuoted(p, coin.Decimals(), quote))
+				} else {
+					coin.DisplayStr = fmt.Sprintf("%s: Parse Error", coin.Symbol)
+				}
+			} else {
+				coin.DisplayStr = fmt.Sprintf("%s: Loading...", coin.Symbol)
+			}
+			coin.IsLoading = coin.LastPrice == ""
+			coin.FetchError = nil
+		}
+		return loadedData.CoinData
+	} else {
+		log.Println("Initializing coin data from scratch.")
+		symbols := dedupeSymbols(internal.TargetSymbols)
+		coinData := make([]*internal.CoinInfo, len(symbols))
+		for i, symbol := range symbols {
+			coinData[i] = &internal.CoinInfo{
+				Symbol:       symbol,
+				DisplayStr:   fmt.Sprintf("%s: Loading...", symbol),
+				IsLoading:    true,
+				PriceHistory: []internal.PricePoint{},
+			}
+		}
+		return coinData
+	}
+}
+
+// priceDirection compares LastPrice with PreviousPrice and returns 1 when the
+// price went up, -1 when it went down and 0 when unchanged or unknown
+func priceDirection(coin *internal.CoinInfo) int {
+	if coin.PreviousPrice == "" || coin.LastPrice == "" {
+		return 0
+	}
+	prev, prevErr := strconv.ParseFloat(coin.PreviousPrice, 64)
+	last, lastErr := strconv.ParseFloat(coin.LastPrice, 64)
+	if prevErr != nil || lastErr != nil {
+		return 0
+	}
+	if last > prev {
+		return 1
+	} else if last < prev {
+		return -1
+	}
+	return 0
+}
+
+// pollCoins splits the coins due this round into those for the batch
+// request and delisted ones due a recheck. The selected coin is due every
+// update interval and the others every background interval, and a coin that
+// just got selected is fetched right away rather than at its background time.
+func (g *Game) pollCoins() (batch, recheck []*internal.CoinInfo) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	for i, coin := range g.coinData {
+		interval := time.Duration(g.config.BackgroundIntervalMs) * time.Millisecond
+		if i == g.SelectedCoinIndex {
+			interval = internal.UpdateInterval()
+		}
+		switch {
+		case !coin.Delisted:
+			// Rounds are an update interval apart, so a coin due before
+			// about halfway to the next one is fetched now
+			if wait := coin.NextUpdate.Sub(now); wait > internal.UpdateInterval()/2 && wait <= interval {
+				continue
+			}
+			coin.NextUpdate = now.Add(interval)
+			batch = append(batch, coin)
+		case time.Since(coin.CheckedAt) >= delistedRecheckInterval:
+			coin.CheckedAt = time.Now()
+			recheck = append(recheck, coin)
+		}
+	}
+	return batch, recheck
+}
+
+// coinSnapshot returns a copy of coinData that can be iterated without holding
+// g.mu while coins are added or removed
+func (g *Game) coinSnapshot() []*internal.CoinInfo {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]*internal.CoinInfo(nil), g.coinData...)
+}
+
+// startStream (re)subscribes the price stream to the current coins. Only
+// Binance has a stream; other sources are polled. Callers must hold g.mu.
+func (g *Game) startStream() {
+	if g.streamCancel != nil {
+		g.streamCancel()
+	}
+	if _, ok := g.source.(internal.BinanceSource); !ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(g.ctx)
+	g.streamCancel = cancel
+	g.stream = internal.NewPriceStream(g.coinSymbols())
+	go g.stream.Run(ctx)
+}
+
+// addCoin validates symbol against the exchange with a price probe and starts
+// tracking it
+func (g *Game) addCoin(symbol string) error {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return fmt.Errorf("empty symbol")
+	}
+
+	g.mu.Lock()
+	for _, coin := range g.coinData {
+		if coin.Symbol == symbol {
+			g.mu.Unlock()
+			return fmt.Errorf("%s is already tracked", symbol)
+		}
+	}
+	g.mu.Unlock()
+
+	if err := g.validateSymbol(symbol); err != nil {
+		return err
+	}
+
+	price, err := g.source.GetPrice(g.ctx, symbol)
+	if errors.Is(err, internal.ErrInvalidSymbol) {
+		return fmt.Errorf("%w: %s not on %s", internal.ErrInvalidSymbol, symbol, g.source.Name())
+	}
+	if err != nil {
+		return fmt.Errorf("could not validate %s: %w", symbol, err)
+	}
+
+	coin := &internal.CoinInfo{
+		Symbol:       symbol,
+		DisplayStr:   fmt.Sprintf("%s: Loading...", symbol),
+		IsLoading:    true,
+		PriceHistory: []internal.PricePoint{},
+	}
+	g.applyPrice(coin, price, nil, true)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.coinData = append(g.coinData, coin)
+	g.dirty = true
+	g.sortCoins()
+	g.startStream()
+	go g.fetchDecimals([]*internal.CoinInfo{coin})
+	log.Printf("Added coin %s", symbol)
+	return nil
+}
+
+// removeCoin stops tracking the coin at index
+func (g *Game) removeCoin(index int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if index < 0 || index >= len(g.coinData) {
+		return
+	}
+	removed := g.coinData[index]
+	g.coinData = append(g.coinData[:index], g.coinData[index+1:]...)
+	g.dirty = true
+
+	if g.SelectedCoinIndex > index || g.SelectedCoinIndex >= len(g.coinData) {
+		g.SelectedCoinIndex--
+	}
+	if g.CompareCoinIndex == index {
+		g.CompareCoinIndex = -1
+	} else if g.CompareCoinIndex > index {
+		g.CompareCoinIndex--
+	}
+	g.sortCoins()
+	g.startStream()
+	g.statusText = fmt.Sprintf("Removed %s", removed.Symbol)
+	log.Printf("Removed coin %s", removed.Symbol)
+}
+
+// loadSymbolInfo fetches every symbol's exchange info once at startup, for
+// validating added symbols and labelling coins
+func (g *Game) loadSymbolInfo() {
+	infos, err := g.source.GetExchangeInfo()
+	if errors.Is(err, internal.ErrUnsupported) {
+		return
+	}
+	if err != nil {
+		log.Printf("Could not load exchange info, symbols won't be validated: %v", err)
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.symbolInfo = infos
+	for _, coin := range g.coinData {
+		if delisted := !g.trading(coin.Symbol); delisted != coin.Delisted {
+			log.Printf("%s trading status: %t", coin.Symbol, !delisted)
+			coin.Delisted = delisted
+			g.dirty = true
+		}
+	}
+	g.sortCoins() // refreshes the dropdown labels
+	log.Printf("Loaded exchange info for %d symbols", len(infos))
+}
+
+// trading reports whether the exchange info lists symbol as trading, or true
+// before it's loaded. Callers must hold g.mu.
+func (g *Game) trading(symbol string) bool {
+	if g.symbolInfo == nil {
+		return true
+	}
+	info, ok := g.symbolInfo[symbol]
+	return ok && info.Status == "TRADING"
+}
+
+// removeDelisted stops tracking every coin that's no longer trading
+func (g *Game) removeDelisted() {
+	for _, coin := range g.coinSnapshot() {
+		if coin.Delisted {
+			g.mu.Lock()
+			index := slices.Index(g.coinData, coin)
+			g.mu.Unlock()
+			g.removeCoin(index)
+		}
+	}
+}
+
+// validateSymbol checks symbol against the exchange info, when it's loaded.
+// Without it addCoin's price probe is the only check.
+func (g *Game) validateSymbol(symbol string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.symbolInfo == nil {
+		return nil
+	}
+	info, ok := g.symbolInfo[symbol]
+	if !ok {
+		return fmt.Errorf("%w: %s not on %s", internal.ErrInvalidSymbol, symbol, g.source.Name())
+	}
+	if info.Status != "TRADING" {
+		return fmt.Errorf("%w: %s is %s", internal.ErrInvalidSymbol, symbol, strings.ToLower(info.Status))
+	}
+	return nil
+}
+
+// coinLabel is the symbol shown for coin in the dropdown, e.g. "ETH/BTC",
+// split by exchange info once it's loaded and by known quote suffixes before
+// that. Callers must hold g.mu.
+func (g *Game) coinLabel(coin *internal.CoinInfo) string {
+	if info, ok := g.symbolInfo[coin.Symbol]; ok && info.BaseAsset != "" && info.QuoteAsset != "" {
+		return info.BaseAsset + "/" + info.QuoteAsset
+	}
+	if base, quote, ok := internal.SplitSymbol(coin.Symbol); ok {
+		return base + "/" + quote
+	}
+	return coin.Symbol
+}
+
+// coinLabels returns coinLabel for each coin in display order. Callers must hold g.mu.
+func (g *Game) coinLabels() []string {
+	labels := make([]string, len(g.coinData))
+	for i, coin := range g.coinData {
+		labels[i] = g.coinLabel(coin)
+	}
+	return labels
+}
+
+// submitSymbolInput adds the typed symbol in the background so the
+// validation request doesn't block the UI
+func (g *Game) submitSymbolInput() {
+	symbol := strings.ToUpper(strings.TrimSpace(g.symbolInput.Text))
+	g.symbolInput.Text = ""
+	g.symbolInput.Focused = false
+
+	g.mu.Lock()
+	g.statusText = fmt.Sprintf("Adding %s...", symbol)
+	g.mu.Unlock()
+
+	go func() {
+		err := g.addCoin(symbol)
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if err != nil {
+			log.Printf("Could not add coin: %v", err)
+			switch {
+			case errors.Is(err, internal.ErrInvalidSymbol):
+				g.statusText = err.Error()
+			case errors.Is(err, internal.ErrRateLimited):
+				g.statusText = "Rate limited, try again shortly"
+			default:
+				g.statusText = "Add failed"
+			}
+			return
+		}
+		g.statusText = fmt.Sprintf("Added %s", symbol)
+	}()
+}
+
+// handleTextInputs updates the topbar text inputs and submits them on Enter
+func (g *Game) handleTextInputs() {
+	if g.symbolInput.Update() {
+		g.submitSymbolInput()
+	}
+	if g.alertInput.Update() {
+		g.submitAlertInput(ebiten.IsKeyPressed(ebiten.KeyShift))
+	}
+	if g.holdingsInput.Update() {
+		g.submitHoldingsInput()
+	}
+}
+
+// textInputFocused reports whether typing currently goes to a text input
+func (g *Game) textInputFocused() bool {
+	return g.symbolInput.Focused || g.alertInput.Focused || g.holdingsInput.Focused
+}
+
+// coinSymbols returns the symbols of coinData in display order
+func (g *Game) coinSymbols() []string {
+	symbols := make([]string, len(g.coinData))
+	for i, coin := range g.coinData {
+		symbols[i] = coin.Symbol
+	}
+	return symbols
+}
+
+// sortCoins moves pinned coins to the top while keeping the relative order of
+// the rest, and keeps the selection and compare coin pointing at the same
+// coins. Callers must hold g.mu.
+func (g *Game) sortCoins() {
+	var selected, compare *internal.CoinInfo
+	if g.SelectedCoinIndex >= 0 && g.SelectedCoinIndex < len(g.coinData) {
+		selected = g.coinData[g.SelectedCoinIndex]
+	}
+	if g.CompareCoinIndex >= 0 && g.CompareCoinIndex < len(g.coinData) {
+		compare = g.coinData[g.CompareCoinIndex]
+	}
+
+	sort.SliceStable(g.coinData, func(i, j int) bool {
+		return g.coinData[i].Pinned && !g.coinData[j].Pinned
+	})
+
+	for i, coin := range g.coinData {
+		if coin == selected {
+			g.SelectedCoinIndex = i
+		}
+		if coin == compare {
+			g.CompareCoinIndex = i
+		}
+	}
+
+	if len(g.dropdowns) > 0 {
+		g.dropdowns[0].Options = g.coinLabels()
+		g.dropdowns[0].Selected = g.SelectedCoinIndex
+		g.topbarStale = true // resized to the new labels on the next Update
+		// New symbols and labels get their glyphs cached on the next Update
+		g.pendingGlyphs = append(g.pendingGlyphs, g.dropdowns[0].Options...)
+	}
+}
+
+// cacheGlyphs renders any runes of strs that haven't been drawn yet offscreen,
+// so their first real draw doesn't hitch. Only call it from the game loop.
+func (g *Game) cacheGlyphs(strs ...string) {
+	var missing []rune
+	for _, s := range strs {
+		for _, r := range s {
+			if !g.cachedGlyphs[r] {
+				g.cachedGlyphs[r] = true
+				missing = append(missing, r)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	text.Draw(ebiten.NewImage(1, 1), string(missing), g.fontFace, &text.DrawOptions{})
+}
+
+// togglePin flips the pinned state of the coin at index and reorders the list
+func (g *Game) togglePin(index int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if index < 0 || index >= len(g.coinData) {
+		return
+	}
+	coin := g.coinData[index]
+	coin.Pinned = !coin.Pinned
+	g.dirty = true
+	log.Printf("Pinned %s: %t", coin.Symbol, coin.Pinned)
+	g.sortCoins()
+}
+
+func (g *Game) initTopbar() {
+	g.chartType = "line"
+	g.timeline = "1h"
+
+	g.dropdowns = []*Dropdown{
+		{
+			ID:      "crypto",
+			Label:   "Crypto",
+			Options: g.coinLabels(),
+			OnSelect: func(index int) {
+				g.mu.Lock()
+				g.SelectedCoinIndex = index
+				g.resetView()
+				g.mu.Unlock()
+			},
+		},
+		{
+			ID:      "chart",
+			Label:   "Chart",
+			Options: []string{"Line", "Candle"},
+			OnSelect: func(index int) {
+				g.mu.Lock()
+				if index == 0 {
+					g.chartType = "line"
+				} else {
+					g.chartType = "candle"
+				}
+				g.mu.Unlock()
+			},
+		},
+		{
+			ID:      "time",
+			Label:   "Time",
+			Options: []string{"1h", "4h", "1d", "1w"},
+			OnSelect: func(index int) {
+				g.mu.Lock()
+				g.timeline = g.dropdowns[2].Options[index]
+				g.resetView()
+				g.mu.Unlock()
+			},
+		},
+		{
+			ID:      "ma",
+			Label:   "MA",
+			Options: []string{"MA Off", "MA7", "MA25", "MA99"},
+			OnSelect: func(index int) {
+				g.mu.Lock()
+				g.maPeriod = maPeriods[index]
+				g.mu.Unlock()
+			},
+		},
+		{
+			ID:      "smooth",
+			Label:   "Smooth",
+			Options: []string{"Raw", "EMA .3", "EMA .1"},
+			OnSelect: func(index int) {
+				g.mu.Lock()
+				g.smoothAlpha = smoothingAlphas[index]
+				g.mu.Unlock()
+			},
+		},
+		{
+			ID:      "rsi",
+			Label:   "RSI",
+			Options: []string{"RSI Off", fmt.Sprintf("RSI %d", defaultRSIPeriod)},
+			OnSelect: func(index int) {
+				g.mu.Lock()
+				g.rsiPeriod = 0
+				if index == 1 {
+					g.rsiPeriod = defaultRSIPeriod
+				}
+				g.mu.Unlock()
+			},
+		},
+		{
+			ID:      "bb",
+			Label:   "BB",
+			Options: []string{"BB Off", fmt.Sprintf("BB %d", bollingerPeriod)},
+			OnSelect: func(index int) {
+				g.mu.Lock()
+				g.showBollinger = index == 1
+				g.mu.Unlock()
+			},
+		},
+		{
+			ID:      "macd",
+			Label:   "MACD",
+			Options: []string{"MACD Off", "MACD"},
+			OnSelect: func(index int) {
+				g.mu.Lock()
+				g.showMACD = index == 1
+				g.mu.Unlock()
+			},
+		},
+		{
+			ID:      "scale",
+			Label:   "Scale",
+			Options: []string{"Linear", "Log"},
+			OnSelect: func(index int) {
+				g.mu.Lock()
+				g.logScale = index == 1
+				g.mu.Unlock()
+			},
+		},
+		{
+			ID:      "currency",
+			Label:   "Currency",
+			Options: currencies,
+			OnSelect: func(index int) {
+				g.selectCurrency(currencies[index])
+			},
+		},
+		{
+			ID:      "view",
+			Label:   "View",
+			Options: []string{"Single", "Grid"},
+			OnSelect: func(index int) {
+				g.mu.Lock()
+				g.setGridView(index == 1)
+				g.mu.Unlock()
+			},
+		},
+	}
+
+	// Symbol input and Add button follow the dropdowns
+	g.symbolInput = &TextInput{
+		Placeholder: "Add symbol",
+		MaxLen:      16,
+		Filter:      symbolFilter,
+	}
+	// Alert price input: Enter sets a one-shot alert, Shift+Enter a repeating one
+	g.alertInput = &TextInput{
+		Placeholder: "Alert price",
+		MaxLen:      16,
+		Filter:      priceFilter,
+	}
+	// Holdings input: Enter sets the quantity held of the selected coin
+	g.holdingsInput = &TextInput{
+		Placeholder: "Holdings",
+		MaxLen:      16,
+		Filter:      priceFilter,
+	}
+}
+
+// layoutTopbar sizes the topbar for deviceScale and places its dropdowns and
+// inputs
+func (g *Game) layoutTopbar() {
+	topbarHeight := 16.0 * g.deviceScale
+	g.topbarHeight = topbarHeight
+
+	// Compact pill-shaped dropdowns with spacing, each as wide as its
+	// longest option
+	g.mu.Lock()
+	widths := make([]int, len(g.dropdowns))
+	for i, dropdown := range g.dropdowns {
+		widths[i] = g.dropdownWidth(dropdown)
+	}
+	g.topbarStale = false
+	g.mu.Unlock()
+
+	margin := 12
+	btnH := int(topbarHeight) - 10
+	x := margin
+	for i, dropdown := range g.dropdowns {
+		dropdown.Bounds = image.Rect(x, 5, x+widths[i], 5+btnH)
+		x += widths[i] + margin
+	}
+
+	inputX := x
+	inputW := 90
+	g.symbolInput.Bounds = image.Rect(inputX, 5, inputX+inputW, 5+btnH)
+	g.addButton = image.Rect(inputX+inputW+6, 5, inputX+inputW+6+40, 5+btnH)
+
+	alertX := g.addButton.Max.X + margin
+	g.alertInput.Bounds = image.Rect(alertX, 5, alertX+inputW, 5+btnH)
+
+	holdingsX := g.alertInput.Bounds.Max.X + 6
+	g.holdingsInput.Bounds = image.Rect(holdingsX, 5, holdingsX+inputW, 5+btnH)
+}
+
+// dropdownWidth fits the widest of dropdown's options, with the open icon
+// and padding. Callers must hold g.mu.
+func (g *Game) dropdownWidth(dropdown *Dropdown) int {
+	widest := 0.0
+	for _, option := range append([]string{"-"}, dropdown.Options...) {
+		w, _ := text.Measure(option+" ▼", g.fontFace, 0)
+		widest = math.Max(widest, w)
+	}
+	return int(math.Ceil(widest)) + 28
+}
+
+// rebuildLayout builds the font face and lays out the topbar for the monitor
+// scale factor. Update calls it again whenever the window moves to a monitor
+// with a different scale.
+func (g *Game) rebuildLayout(scale float64) error {
+	scaledFontSize := baseFontSize * scale
+	fontFace, err := esset.GetFont(g.fontData, int(scaledFontSize))
+	if err != nil {
+		return fmt.Errorf("font could not be loaded with scaled size %f: %w", scaledFontSize, err)
+	}
+
+	g.fontFace = fontFace
+	g.physicalLineHeight = scaledFontSize*1.5 + 5.0*scale
+	g.deviceScale = scale
+	g.bigFontFace = nil
+	g.layoutTopbar()
+
+	// The new face starts with an empty glyph cache
+	g.cachedGlyphs = make(map[rune]bool)
+	g.mu.Lock()
+	g.pendingGlyphs = append(g.pendingGlyphs, glyphsToPreload)
+	g.pendingGlyphs = append(g.pendingGlyphs, g.coinSymbols()...)
+	g.mu.Unlock()
+	return nil
+}
+
+// toggleTheme switches between the dark and light theme and saves the choice
+func (g *Game) toggleTheme() {
+	if g.theme.Name == lightTheme.Name {
+		g.theme = darkTheme
+	} else {
+		g.theme = lightTheme
+	}
+	g.config.Theme = g.theme.Name
+	if err := saveConfig(g.config, configFilename); err != nil {
+		log.Printf("Error saving config: %v", err)
+	}
+}
+
+// drawTextInput draws a text input box with its text, caret or placeholder
+func (g *Game) drawTextInput(screen *ebiten.Image, input *TextInput) {
+	inputColor := g.theme.Control
+	if input.Focused {
+		inputColor = g.theme.ControlActive
+	}
+	ib := input.Bounds
+	vector.DrawFilledRect(screen, float32(ib.Min.X), float32(ib.Min.Y), float32(ib.Dx()), float32(ib.Dy()), inputColor, false)
+	vector.StrokeRect(screen, float32(ib.Min.X), float32(ib.Min.Y), float32(ib.Dx()), float32(ib.Dy()), 1.5, g.theme.Border, false)
+	inputText, inputTextColor := input.Text, g.theme.TextPrimary
+	if input.Focused {
+		inputText += "_"
+	} else if inputText == "" {
+		inputText, inputTextColor = input.Placeholder, g.theme.TextMuted
+	}
+	esset.DrawText(screen, inputText, 0, float64(ib.Min.X+8), float64(ib.Min.Y+6), g.fontFace, inputTextColor)
+}
+
+func (g *Game) drawTopbar(screen *ebiten.Image) {
+	screenWidth, _ := screen.Bounds().Dx(), screen.Bounds().Dy()
+	// Topbar background
+	vector.DrawFilledRect(screen, 0, 0, float32(screenWidth), float32(g.topbarHeight), g.theme.Topbar, false)
+	// Draw dropdowns
+	for _, dropdown := range g.dropdowns {
+		// Pill background
+		pillColor := g.theme.Control
+		if dropdown.IsOpen {
+			pillColor = g.theme.ControlActive
+		}
+		vector.DrawFilledRect(screen, float32(dropdown.Bounds.Min.X), float32(dropdown.Bounds.Min.Y),
+			float32(dropdown.Bounds.Dx()), float32(dropdown.Bounds.Dy()), pillColor, false)
+		// Subtle shadow
+		vector.StrokeRect(screen, float32(dropdown.Bounds.Min.X), float32(dropdown.Bounds.Min.Y),
+			float32(dropdown.Bounds.Dx()), float32(dropdown.Bounds.Dy()), 1.5, g.theme.Border, false)
+		// Value + icon (no label prefix)
+		value := "-"
+		if dropdown.Selected >= 0 && dropdown.Selected < len(dropdown.Options) {
+			value = dropdown.Options[dropdown.Selected]
+		}
+		icon := " ▼"
+		if dropdown.IsOpen && dropdown.Filter != "" {
+			value, icon = dropdown.Filter, "_"
+		}
+		esset.DrawText(screen, value+icon, 0, float64(dropdown.Bounds.Min.X+14), float64(dropdown.Bounds.Min.Y+6), g.fontFace, g.theme.TextPrimary)
+	}
+	// Symbol input, Add button, alert and holdings inputs
+	g.drawTextInput(screen, g.symbolInput)
+	ab := g.addButton
+	vector.DrawFilledRect(screen, float32(ab.Min.X), float32(ab.Min.Y), float32(ab.Dx()), float32(ab.Dy()), g.theme.Control, false)
+	esset.DrawText(screen, "Add", 0, float64(ab.Min.X+8), float64(ab.Min.Y+6), g.fontFace, g.theme.TextPrimary)
+	g.drawTextInput(screen, g.alertInput)
+	g.drawTextInput(screen, g.holdingsInput)
+
+	// Portfolio total, then the status of the last action
+	g.mu.Lock()
+	infoX := float64(g.holdingsInput.Bounds.Max.X + 8)
+	if summary := g.portfolioSummary(); summary != "" {
+		esset.DrawText(screen, summary, 0, infoX, float64(ab.Min.Y+6), g.fontFace, g.theme.TextPrimary)
+		summaryWidth, _ := text.Measure(summary, g.fontFace, 0)
+		infoX += summaryWidth + 12
+	}
+	if g.statusText != "" {
+		esset.DrawText(screen, g.statusText, 0, infoX, float64(ab.Min.Y+6), g.fontFace, g.theme.TextSecondary)
+	}
+	g.mu.Unlock()
+
+	// Connection status, left of the price info, and the spread left of it
+	g.mu.Lock()
+	status, statusColor := g.connectionStatus()
+	spread := ""
+	if g.config.ShowSpread {
+		spread = g.spreadText()
+	}
+	g.mu.Unlock()
+	esset.DrawText(screen, status, 0, float64(screenWidth-432), float64(ab.Min.Y+6), g.fontFace, statusColor)
+	if spread != "" {
+		spreadWidth, _ := text.Measure(spread, g.fontFace, 0)
+		esset.DrawText(screen, spread, 0, float64(screenWidth-444)-spreadWidth, float64(ab.Min.Y+6), g.fontFace, g.theme.TextSecondary)
+	}
+
+	// Theme toggle at the right edge
+	themeLabel := "Light"
+	if g.theme.Name == lightTheme.Name {
+		themeLabel = "Dark"
+	}
+	g.themeButton = image.Rect(screenWidth-60, 5, screenWidth-12, ab.Max.Y)
+	tb := g.themeButton
+	vector.DrawFilledRect(screen, float32(tb.Min.X), float32(tb.Min.Y), float32(tb.Dx()), float32(tb.Dy()), g.theme.Control, false)
+	esset.DrawText(screen, themeLabel, 0, float64(tb.Min.X+8), float64(tb.Min.Y+6), g.fontFace, g.theme.TextPrimary)
+
+	// Settings gear
+	g.settingsButton = image.Rect(tb.Min.X-36, tb.Min.Y, tb.Min.X-6, tb.Max.Y)
+	sb := g.settingsButton
+	gearBackground := g.theme.Control
+	if g.settings.IsOpen {
+		gearBackground = g.theme.ControlActive
+	}
+	vector.DrawFilledRect(screen, float32(sb.Min.X), float32(sb.Min.Y), float32(sb.Dx()), float32(sb.Dy()), gearBackground, false)
+	drawGear(screen, sb, g.theme.TextPrimary, gearBackground)
+
+	// Pause toggle, two bars while running and a play triangle while paused
+	g.pauseButton = image.Rect(sb.Min.X-36, sb.Min.Y, sb.Min.X-6, sb.Max.Y)
+	pb := g.pauseButton
+	g.mu.Lock()
+	paused := g.paused
+	g.mu.Unlock()
+	pauseBackground := g.theme.Control
+	if paused {
+		pauseBackground = g.theme.ControlActive
+	}
+	vector.DrawFilledRect(screen, float32(pb.Min.X), float32(pb.Min.Y), float32(pb.Dx()), float32(pb.Dy()), pauseBackground, false)
+	g.drawPauseIcon(screen, pb, paused, g.theme.TextPrimary)
+
+	// Draw price info, small and right-aligned. The fetchers update the coin
+	// under g.mu, so draw from a copy taken while holding it
+	g.mu.Lock()
+	var selectedCoin internal.CoinInfo
+	priceInfo, priceColor := "", g.theme.TextPrimary
+	if g.SelectedCoinIndex >= 0 && g.SelectedCoinIndex < len(g.coinData) {
+		selectedCoin = *g.coinData[g.SelectedCoinIndex]
+		priceInfo = fmt.Sprintf("%s: %s", selectedCoin.Symbol, selectedCoin.LastPrice)
+		if p, err := strconv.ParseFloat(selectedCoin.LastPrice, 64); err == nil {
+			priceInfo = fmt.Sprintf("%s: %s", selectedCoin.Symbol, formatQuoted(p, selectedCoin.Decimals(), g.quoteAsset(&selectedCoin)))
+		}
+		switch priceDirection(&selectedCoin) {
+		case 1:
+			priceColor = g.directionColor(true)
+		case -1:
+			priceColor = g.directionColor(false)
+		}
+	}
+	g.mu.Unlock()
+	if priceInfo != "" {
+		g.drawTickFlash(screen, &selectedCoin, priceInfo, float64(screenWidth-302), 10)
+		esset.DrawText(screen, priceInfo, 12, float64(screenWidth-302), 10, g.fontFace, priceColor)
+	}
+}
+
+// drawTickFlash highlights the background of label at x, y in the up or down
+// color right after coin's price moves, fading out over tickFlashDuration
+func (g *Game) drawTickFlash(screen *ebiten.Image, coin *internal.CoinInfo, label string, x, y float64) {
+	elapsed := time.Since(coin.ChangedAt)
+	if coin.ChangeDir == 0 || elapsed >= tickFlashDuration {
+		return
+	}
+	base := g.directionColor(coin.ChangeDir > 0)
+	flash := color.NRGBA{base.R, base.G, base.B, uint8(110 * (1 - float64(elapsed)/float64(tickFlashDuration)))}
+
+	w, h := text.Measure(label, g.fontFace, 0)
+	vector.DrawFilledRect(screen, float32(x-4), float32(y-2), float32(w+8), float32(h+4), flash, false)
+}
+
+// connectionStatus summarizes the latest fetch results across all coins as
+// Live, Degraded or Offline, along with how old the data is once it's stale.
+// Callers must hold g.mu.
+func (g *Game) connectionStatus() (string, color.RGBA) {
+	fetched, failed := 0, 0
+	for _, coin := range g.coinData {
+		// Errored coins being retried still count as failed, delisted ones
+		// aren't fetched at all
+		if (coin.IsLoading && coin.FetchError == nil) || coin.Delisted {
+			continue
+		}
+		fetched++
+		if coin.FetchError != nil {
+			failed++
+		}
+	}
+
+	status, statusColor := "Live", g.theme.Up
+	switch {
+	case fetched == 0:
+		status, statusColor = "Connecting", g.theme.TextMuted
+	case failed == fetched:
+		status, statusColor = "Offline", g.theme.Down
+	case failed > 0:
+		status, statusColor = "Degraded", g.theme.Warning
+	}
+
+	if g.paused {
+		return "Paused", g.theme.Warning
+	}
+	if g.failStreak >= pollFailureThreshold {
+		wait := max(time.Until(g.nextPoll), 0)
+		return fmt.Sprintf("Offline, reconnecting in %ds", int(math.Ceil(wait.Seconds()))), g.theme.Down
+	}
+	if g.lastSuccessfulUpdate.IsZero() {
+		if fetched > 0 {
+			status += " (no data)"
+		}
+	} else if age := time.Since(g.lastSuccessfulUpdate); age > 2*internal.UpdateInterval() {
+		status += fmt.Sprintf(" (%s ago)", age.Round(time.Second))
+	}
+	if internal.RateLimitState().Throttled() {
+		status += " throttled"
+		statusColor = g.theme.Warning
+	}
+	return status, statusColor
+}
+
+// selectOption picks option index of dropdown and closes it
+func (g *Game) selectOption(dropdown *Dropdown, index int) {
+	dropdown.Selected = index
+	if dropdown.OnSelect != nil {
+		dropdown.OnSelect(index)
+	}
+	dropdown.close()
+	g.activeDropdown = nil
+}
+
+// handleDropdownKeys lets the open dropdown be filtered by typing and
+// navigated with the arrow keys, Enter and Escape
+func (g *Game) handleDropdownKeys() {
+	dropdown := g.activeDropdown
+	if dropdown == nil {
+		return
+	}
+
+	filter := dropdown.Filter
+	for _, r := range ebiten.AppendInputChars(nil) {
+		dropdown.Filter += string(r)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(dropdown.Filter) > 0 {
+		dropdown.Filter = dropdown.Filter[:len(dropdown.Filter)-1]
+	}
+	if dropdown.Filter != filter {
+		dropdown.Highlight = 0
+	}
+
+	visible := dropdown.visibleOptions()
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		dropdown.Highlight++
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		dropdown.Highlight--
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if dropdown.Highlight < len(visible) {
+			g.selectOption(dropdown, visible[dropdown.Highlight])
+		}
+		return
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		dropdown.close()
+		g.activeDropdown = nil
+		return
+	}
+	highlight := dropdown.Highlight
+	dropdown.Highlight = max(0, min(dropdown.Highlight, len(visible)-1))
+	if dropdown.Highlight != highlight || dropdown.Filter != filter {
+		dropdown.scrollToHighlight()
+	}
+	// Options can also go away while open, e.g. a removed coin
+	dropdown.scroll(0)
+
+	// The wheel scrolls the list while over it, a row per notch
+	if _, dy := ebiten.Wheel(); dy != 0 && image.Pt(ebiten.CursorPosition()).In(g.optionsPanel(dropdown)) {
+		if dy > 0 {
+			dropdown.scroll(-1)
+		} else {
+			dropdown.scroll(1)
+		}
+	}
+}
+
+// drawOpenDropdown draws the open dropdown's option list. It's drawn last so
+// it sits above the chart and coin list. Callers must hold g.mu.
+func (g *Game) drawOpenDropdown(screen *ebiten.Image) {
+	dropdown := g.activeDropdown
+	if dropdown == nil {
+		return
+	}
+
+	panel := g.optionsPanel(dropdown)
+	vector.DrawFilledRect(screen, float32(panel.Min.X), float32(panel.Min.Y), float32(panel.Dx()), float32(panel.Dy()), g.theme.Card, false)
+	vector.StrokeRect(screen, float32(panel.Min.X), float32(panel.Min.Y), float32(panel.Dx()), float32(panel.Dy()), 1, g.theme.Border, false)
+
+	visible := dropdown.visibleOptions()
+	if len(visible) == 0 {
+		esset.DrawText(screen, "No matches", 0, float64(panel.Min.X+14), float64(panel.Min.Y+6), g.fontFace, g.theme.TextMuted)
+	}
+	first, end := dropdown.shownRows()
+	for row := first; row < end; row++ {
+		i := visible[row]
+		optionRect := g.optionRect(dropdown, row)
+		if row == dropdown.Highlight {
+			vector.DrawFilledRect(screen, float32(optionRect.Min.X), float32(optionRect.Min.Y),
+				float32(optionRect.Dx()), float32(optionRect.Dy()), g.theme.ControlActive, false)
+		}
+		esset.DrawText(screen, dropdown.Options[i], 0, float64(optionRect.Min.X+14), float64(optionRect.Min.Y+6), g.fontFace, g.theme.TextPrimary)
+		// Pin toggle for coin rows
+		if dropdown.ID == "crypto" && i < len(g.coinData) {
+			pinColor := g.theme.TextMuted
+			if g.coinData[i].Pinned {
+				pinColor = g.theme.Pin
+			}
+			esset.DrawText(screen, "*", 0, float64(optionRect.Max.X)-(pinZoneBaseWidth-4)*g.deviceScale, float64(optionRect.Min.Y+6), g.fontFace, pinColor)
+		}
+	}
+
+	// Scrollbar along the right edge when some options are out of view
+	if n := len(visible); n > maxDropdownRows {
+		width := float32(3 * g.deviceScale)
+		thumbH := float32(panel.Dy()) * maxDropdownRows / float32(n)
+		thumbY := float32(panel.Min.Y) + float32(panel.Dy())*float32(first)/float32(n)
+		vector.DrawFilledRect(screen, float32(panel.Max.X)-width-1, thumbY, width, thumbH, g.theme.TextMuted, false)
+	}
+}
+
+// handleTopbarInput handles the topbar widgets and the open dropdown list,
+// reporting whether it consumed this frame's click
+func (g *Game) handleTopbarInput() bool {
+	g.handleTextInputs()
+
+	// Right-clicking a coin in the open Crypto dropdown removes it
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) && g.activeDropdown != nil && g.activeDropdown.ID == "crypto" {
+		mx, my := ebiten.CursorPosition()
+		if row, ok := g.optionRow(g.activeDropdown, image.Pt(mx, my)); ok {
+			g.removeCoin(g.activeDropdown.visibleOptions()[row])
+		}
+		return true
+	}
+
+	g.handleDropdownKeys()
+
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return false
+	}
+	mx, my := ebiten.CursorPosition()
+	cursor := image.Pt(mx, my)
+
+	// The open option list is drawn on top of everything, so it gets the
+	// click first and blocks it from reaching the widgets behind it
+	if dropdown := g.activeDropdown; dropdown != nil {
+		if row, ok := g.optionRow(dropdown, cursor); ok {
+			optionIndex := dropdown.visibleOptions()[row]
+			// Clicking the pin area toggles the pin and keeps the list open
+			if dropdown.ID == "crypto" && float64(mx) >= float64(dropdown.Bounds.Max.X)-pinZoneBaseWidth*g.deviceScale {
+				g.togglePin(optionIndex)
+				return true
+			}
+			g.selectOption(dropdown, optionIndex)
+			return true
+		}
+		if cursor.In(g.optionsPanel(dropdown)) {
+			return true
+		}
+	}
+
+	g.symbolInput.Focused = cursor.In(g.symbolInput.Bounds)
+	g.alertInput.Focused = cursor.In(g.alertInput.Bounds)
+	g.holdingsInput.Focused = cursor.In(g.holdingsInput.Bounds)
+	if g.textInputFocused() {
+		return true
+	}
+	if cursor.In(g.themeButton) {
+		g.toggleTheme()
+		return true
+	}
+	if cursor.In(g.pauseButton) {
+		g.togglePause()
+		return true
+	}
+	if cursor.In(g.settingsButton) {
+		if g.activeDropdown != nil {
+			g.activeDropdown.close()
+			g.activeDropdown = nil
+		}
+		g.settings.open(g)
+		return true
+	}
+	if cursor.In(g.addButton) {
+		if g.symbolInput.Text != "" {
+			g.submitSymbolInput()
+		}
+		return true
+	}
+
+	// Toggle a dropdown when clicking its button
+	for _, dropdown := range g.dropdowns {
+		if !cursor.In(dropdown.Bounds) {
+			continue
+		}
+		if dropdown.IsOpen {
+			dropdown.close()
+			g.activeDropdown = nil
+		} else {
+			if g.activeDropdown != nil {
+				g.activeDropdown.close()
+			}
+			dropdown.open()
+			g.activeDropdown = dropdown
+		}
+		return true
+	}
+
+	// Clicking elsewhere only closes the open dropdown
+	if g.activeDropdown != nil {
+		g.activeDropdown.close()
+		g.activeDropdown = nil
+		return true
+	}
+	return false
+}
+
+// optionHeight is the height of one row in an open dropdown's option list
+func (g *Game) optionHeight() int {
+	return max(int(g.physicalLineHeight*0.85), 1)
+}
+
+// optionRect is where row of dropdown's option list is drawn and clicked,
+// after scrolling. drawOpenDropdown and optionRow both go through it so they
+// can't drift apart.
+func (g *Game) optionRect(dropdown *Dropdown, row int) image.Rectangle {
+	top := dropdown.Bounds.Max.Y + 2 + (row-dropdown.ScrollOffset)*g.optionHeight()
+	return image.Rect(dropdown.Bounds.Min.X, top, dropdown.Bounds.Max.X, top+g.optionHeight())
+}
+
+// shownRows is the range of dropdown's visibleOptions rows in view
+func (d *Dropdown) shownRows() (first, end int) {
+	return d.ScrollOffset, min(d.ScrollOffset+maxDropdownRows, len(d.visibleOptions()))
+}
+
+// optionsPanel is the area covered by dropdown's open option list
+func (g *Game) optionsPanel(dropdown *Dropdown) image.Rectangle {
+	first, end := dropdown.shownRows()
+	return g.optionRect(dropdown, first).Union(g.optionRect(dropdown, max(end-1, first)))
+}
+
+// optionRow returns the row of dropdown's option list under cursor
+func (g *Game) optionRow(dropdown *Dropdown, cursor image.Point) (int, bool) {
+	first, end := dropdown.shownRows()
+	for row := first; row < end; row++ {
+		if cursor.In(g.optionRect(dropdown, row)) {
+			return row, true
+		}
+	}
+	return 0, false
+}
+
+// drawCoinList draws each coin's DisplayStr with its 24h change, at the same
+// row positions Update uses for click selection. Callers must hold g.mu.
+func (g *Game) drawCoinList(screen *ebiten.Image) {
+	startY := g.coinListTop()
+	x := 10.0 * g.deviceScale
+
+	rowText := func(coin *internal.CoinInfo) string {
+		if coin.Delisted {
+			return coin.Symbol + " (delisted)"
+		}
+		if g.staleFor(coin) > 0 {
+			return coin.DisplayStr + " (stale)"
+		}
+		return coin.DisplayStr
+	}
+
+	// Arrows and sparklines share columns past the widest row so they line up
+	maxTextWidth := 0.0
+	for _, coin := range g.coinData {
+		w, _ := text.Measure(rowText(coin), g.fontFace, 0)
+		maxTextWidth = math.Max(maxTextWidth, w)
+	}
+	arrowX := x + maxTextWidth + 6
+	arrowWidth, _ := text.Measure("▲", g.fontFace, 0)
+	sparkX := arrowX + arrowWidth + 8
+	sparkW := sparklineBaseWidth * g.deviceScale
+	portfolioTotal, portfolioValues, _ := g.portfolioValue()
+	hovered := -1
+	if g.rowDrag == nil || !g.rowDrag.moved {
+		hovered = g.coinRowAt(ebiten.CursorPosition())
+	}
+
+	for i, coin := range g.coinData {
+		y := startY + float64(i)*g.physicalLineHeight
+		if i == hovered {
+			row := g.coinRowBounds(i)
+			vector.DrawFilledRect(screen, float32(row.Min.X), float32(row.Min.Y), float32(row.Dx()), float32(row.Dy()), g.theme.Control, false)
+		}
+
+		textColor := g.theme.TextSecondary
+		if i == g.SelectedCoinIndex {
+			textColor = g.theme.TextPrimary
+		}
+		if i == g.CompareCoinIndex && g.compareCoin() != nil {
+			textColor = compareColor
+		}
+		if coin.Delisted || g.staleFor(coin) > 0 || (g.rowDrag != nil && g.rowDrag.moved && g.rowDrag.index == i) {
+			textColor = g.theme.TextMuted
+		}
+		esset.DrawText(screen, rowText(coin), 0, x, y, g.fontFace, textColor)
+		if !coin.Delisted {
+			// Direction by shape as well as color
+			arrow, arrowColor := "–", g.theme.TextMuted
+			switch priceDirection(coin) {
+			case 1:
+				arrow, arrowColor = "▲", g.directionColor(true)
+			case -1:
+				arrow, arrowColor = "▼", g.directionColor(false)
+			}
+			esset.DrawText(screen, arrow, 0, arrowX, y, g.fontFace, arrowColor)
+		}
+		if coin.Pinned {
+			esset.DrawText(screen, "*", 0, 2*g.deviceScale, y, g.fontFace, g.theme.Pin)
+		}
+
+		_, textHeight := text.Measure(coin.DisplayStr, g.fontFace, 0)
+		if n := len(coin.PriceHistory); n > 1 {
+			points := coin.PriceHistory[max(n-sparklinePoints, 0):]
+			trendColor := g.directionColor(points[len(points)-1].Price >= points[0].Price)
+			g.drawSparkline(screen, chartRect{sparkX, y, sparkW, textHeight}, points, trendColor)
+		}
+
+		nextX := sparkX + sparkW + 8
+		if coin.IsLoading {
+			radius := textHeight / 3
+			g.drawSpinner(screen, nextX+radius, y+textHeight/2, radius)
+			nextX += 2*radius + 8
+		}
+
+		if coin.Ticker24h != nil {
+			change := coin.Ticker24h.PriceChangePercent
+			changeColor := g.directionColor(change >= 0)
+			esset.DrawText(screen, fmt.Sprintf("%+.2f%%", change), 0, nextX, y, g.fontFace, changeColor)
+			changeWidth, _ := text.Measure("+00.00%", g.fontFace, 0)
+			nextX += changeWidth + 8
+		}
+
+		// Share of the portfolio total
+		if value, ok := portfolioValues[coin]; ok && portfolioTotal > 0 {
+			esset.DrawText(screen, fmt.Sprintf("(%.1f%%)", value/portfolioTotal*100), 0, nextX, y, g.fontFace, g.theme.TextMuted)
+		}
+		if retryable(coin) {
+			g.drawRetryButton(screen, g.retryButton(i))
+		}
+	}
+
+	if g.rowDrag != nil && g.rowDrag.index < len(g.coinData) {
+		g.drawRowDrag(screen, x, maxTextWidth, rowText(g.coinData[g.rowDrag.index]))
+	}
+}
+
+// staleFor returns how long coin has gone without a new point once that's
+// over staleIntervals update intervals, and 0 while it's fresh or has no
+// history yet, updates are paused or the coin is delisted. Failed fetches
+// don't add points, so errors make a coin stale. Callers must hold g.mu.
+func (g *Game) staleFor(coin *internal.CoinInfo) time.Duration {
+	n := len(coin.PriceHistory)
+	if n == 0 || g.paused || coin.Delisted {
+		return 0
+	}
+	age := time.Since(coin.PriceHistory[n-1].Timestamp)
+	if age <= staleIntervals*internal.UpdateInterval() {
+		return 0
+	}
+	return age
+}
+
+// drawStaleBanner warns along the bottom of the chart that the selected coin
+// stopped getting data, so a flat line isn't mistaken for a quiet market
+func (g *Game) drawStaleBanner(screen *ebiten.Image, area chartRect, age time.Duration) {
+	height := g.physicalLineHeight + 12
+	top := area.top + area.height - height
+	vector.DrawFilledRect(screen, float32(area.left), float32(top), float32(area.width), float32(height), g.theme.StaleBanner, false)
+	message := fmt.Sprintf("No new data for %s, prices may be out of date", age.Round(time.Second))
+	esset.DrawText(screen, message, 0, area.left+12, top+6, g.fontFace, g.theme.StaleText)
+}
+
+// drawPausedBadge marks the chart as frozen while updates are paused
+func (g *Game) drawPausedBadge(screen *ebiten.Image, area chartRect) {
+	const label = "PAUSED"
+	labelWidth, _ := text.Measure(label, g.fontFace, 0)
+	width, height := labelWidth+24, g.physicalLineHeight+12
+	left := area.left + (area.width-width)/2
+	top := area.top + 8
+	badgeColor := g.theme.Warning
+	badgeColor.A = 230
+	vector.DrawFilledRect(screen, float32(left), float32(top), float32(width), float32(height), badgeColor, false)
+	esset.DrawText(screen, label, 0, left+12, top+6, g.fontFace, g.theme.OnWarning)
+}
+
+// refreshWindow returns the wait the selected coin's next price is at the
+// end of: the stream's history sampling interval, or the polling round
+// including any backoff and the coin's own schedule. ok is false while
+// paused or before the first round. Callers must hold g.mu.
+func (g *Game) refreshWindow() (start, end time.Time, ok bool) {
+	if g.paused {
+		return time.Time{}, time.Time{}, false
+	}
+	if g.stream != nil && g.stream.Connected() {
+		return g.lastUpdateTime, g.lastUpdateTime.Add(internal.UpdateInterval()), true
+	}
+	if g.lastPoll.IsZero() {
+		return time.Time{}, time.Time{}, false
+	}
+	end = g.nextPoll
+	if g.SelectedCoinIndex >= 0 && g.SelectedCoinIndex < len(g.coinData) {
+		if next := g.coinData[g.SelectedCoinIndex].NextUpdate; next.After(end) {
+			end = next
+		}
+	}
+	return g.lastPoll, end, true
+}
+
+// drawRefreshBar draws a thin bar along the bottom of the topbar that
+// shrinks until the next price refresh. Callers must hold g.mu.
+func (g *Game) drawRefreshBar(screen *ebiten.Image) {
+	start, end, ok := g.refreshWindow()
+	if !ok || !end.After(start) {
+		return
+	}
+	remaining := math.Min(math.Max(float64(time.Until(end))/float64(end.Sub(start)), 0), 1)
+	height := 2 * g.deviceScale
+	width := float64(screen.Bounds().Dx()) * remaining
+	vector.DrawFilledRect(screen, 0, float32(g.topbarHeight-height), float32(width), float32(height), g.theme.Accent, false)
+}
+
+// drawPauseIcon draws pause bars centered in r, or a play triangle when paused
+func (g *Game) drawPauseIcon(screen *ebiten.Image, r image.Rectangle, paused bool, clr color.RGBA) {
+	cx, cy := float32(r.Min.X+r.Dx()/2), float32(r.Min.Y+r.Dy()/2)
+	size := float32(min(r.Dx(), r.Dy())) * 0.4
+	if !paused {
+		bar := size / 3
+		vector.DrawFilledRect(screen, cx-size/2, cy-size/2, bar, size, clr, false)
+		vector.DrawFilledRect(screen, cx+size/2-bar, cy-size/2, bar, size, clr, false)
+		return
+	}
+
+	play := &vector.Path{}
+	play.MoveTo(cx-size/2, cy-size/2)
+	play.LineTo(cx+size/2, cy)
+	play.LineTo(cx-size/2, cy+size/2)
+	play.Close()
+	vs, is := play.AppendVerticesAndIndicesForFilling(nil, nil)
+	op := &ebiten.DrawTrianglesOptions{AntiAlias: true}
+	op.ColorM.Scale(float64(clr.R)/255.0, float64(clr.G)/255.0, float64(clr.B)/255.0, float64(clr.A)/255.0)
+	screen.DrawTriangles(vs, is, g.solidColorImage, op)
+}
+
+// drawSparkline draws points as a thin line filling rect, scaled to their own
+// min/max like the main chart
+func (g *Game) drawSparkline(screen *ebiten.Image, rect chartRect, points []internal.PricePoint, clr color.RGBA) {
+	points = downsampleLTTB(points, max(int(rect.width), 3))
+	values := make([]float64, len(points))
+	minPrice, maxPrice := points[0].Price, points[0].Price
+	for i, pp := range points {
+		values[i] = pp.Price
+		minPrice = math.Min(minPrice, pp.Price)
+		maxPrice = math.Max(maxPrice, pp.Price)
+	}
+	if maxPrice == minPrice {
+		minPrice -= 0.001
+		maxPrice += 0.001
+	}
+	g.strokeSeries(screen, rect, values, minPrice, maxPrice, float32(g.deviceScale), clr)
+}
+
+// drawSpinner draws a ring of dots around cx, cy with a bright head that
+// rotates over time
+func (g *Game) drawSpinner(screen *ebiten.Image, cx, cy, radius float64) {
+	const dots = 8
+	head := int(time.Now().UnixMilli()/100) % dots
+	dotRadius := float32(math.Max(radius/4, 1))
+	base := g.theme.TextPrimary
+	for i := range dots {
+		angle := 2 * math.Pi * float64(i) / dots
+		// Dots fade out behind the head
+		clr := color.NRGBA{base.R, base.G, base.B, uint8(255 * (dots - (head-i+dots)%dots) / dots)}
+		dx, dy := float32(cx+radius*math.Cos(angle)), float32(cy+radius*math.Sin(angle))
+		vector.DrawFilledCircle(screen, dx, dy, dotRadius, clr, false)
+	}
+}
+
+// drawBigNumber renders only the selected coin's price, centered and scaled to
+// the window, on a background tinted by the last price direction
+func (g *Game) drawBigNumber(screen *ebiten.Image) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	bgColor := g.theme.Background
+	priceStr := "-"
+	if g.SelectedCoinIndex >= 0 && g.SelectedCoinIndex < len(g.coinData) {
+		selectedCoin := g.coinData[g.SelectedCoinIndex]
+		switch priceDirection(selectedCoin) {
+		case 1:
+			bgColor = g.theme.BigUp
+		case -1:
+			bgColor = g.theme.BigDown
+		}
+		if p, err := strconv.ParseFloat(selectedCoin.LastPrice, 64); err == nil {
+			priceStr = formatQuoted(p, selectedCoin.Decimals(), g.quoteAsset(selectedCoin))
+		}
+	}
+	screen.Fill(bgColor)
+
+	size := screen.Bounds().Size()
+	if g.bigFontFace == nil || g.bigFontFaceSize != size {
+		// Fit the longest expected price string into the window
+		fontSize := math.Min(float64(size.Y)*0.4, float64(size.X)/(float64(len(priceStr)+2)*0.6))
+		face, err := esset.GetFont(g.fontData, int(math.Max(fontSize, 1)))
+		if err != nil {
+			log.Printf("Could not build big number font: %v", err)
+			return
+		}
+		g.bigFontFace = face
+		g.bigFontFaceSize = size
+	}
+
+	w, h := text.Measure(priceStr, g.bigFontFace, 0)
+	textColor := g.theme.TextPrimary
+	if bgColor != g.theme.Background {
+		textColor = g.theme.BigText
+	}
+	esset.DrawText(screen, priceStr, 0, (float64(size.X)-w)/2, (float64(size.Y)-h)/2, g.bigFontFace, textColor)
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	g.initSolidColorImage()
+	if g.showPerf {
+		defer g.drawPerfOverlay(screen)
+	}
+	if g.showHelp {
+		defer g.drawHelp(screen)
+	}
+
+	if g.bigNumberMode {
+		g.drawBigNumber(screen)
+		return
+	}
+
+	screen.Fill(g.theme.Background)
+	g.drawTopbar(screen)
+
+	chartPadding := 32.0 * g.deviceScale
+	chartTop := g.topbarHeight + chartPadding
+	chartLeft := coinListBaseWidth*g.deviceScale + chartPadding
+	screenWidth, screenHeight := screen.Size()
+	chartWidth := float64(screenWidth) - chartLeft - chartPadding
+	chartHeight := float64(screenHeight) - chartTop - chartPadding
+	// Labels on the right need room the padding alone doesn't give
+	if g.config.AxisSide != axisLeft {
+		chartWidth -= axisLabelWidth * g.deviceScale
+	}
+
+	// Each indicator pane takes a quarter of the chart space off the bottom,
+	// RSI lowest and MACD right below the chart
+	g.mu.Lock()
+	rsiPeriod, showMACD, gridView := g.rsiPeriod, g.showMACD, g.gridView
+	showOverview := !gridView && g.chartType == "line"
+	compact := g.config.CompactMode
+	g.mu.Unlock()
+	// The grid view gets the whole area, without indicator panes
+	if gridView {
+		rsiPeriod, showMACD = 0, false
+	}
+	paneHeight := chartHeight * 0.25
+	addPane := func(height float64) chartRect {
+		chartHeight -= height + chartPadding
+		return chartRect{chartLeft, chartTop + chartHeight + chartPadding, chartWidth, height}
+	}
+	var rsiArea, macdArea, overviewArea chartRect
+	if rsiPeriod > 0 {
+		rsiArea = addPane(paneHeight)
+	}
+	if showMACD {
+		macdArea = addPane(paneHeight)
+	}
+	// The overview brush sits right under the chart it controls
+	if showOverview {
+		overviewArea = addPane(overviewBaseHeight * g.deviceScale)
+	}
+
+	// Card-like chart area
+	if !gridView && !compact {
+		vector.DrawFilledRect(screen, float32(chartLeft), float32(chartTop), float32(chartWidth), float32(chartHeight), g.theme.Card, false)
+		vector.StrokeRect(screen, float32(chartLeft), float32(chartTop), float32(chartWidth), float32(chartHeight), 2, g.theme.ControlActive, false)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	defer func() {
+		if g.settings.IsOpen {
+			g.settings.Draw(screen, g)
+		}
+	}()
+	defer g.drawOpenDropdown(screen)
+	defer g.drawContextMenu(screen)
+	defer g.drawFetchErrorTip(screen)
+	// Runs before the deferred overlays above, so they stay out of the image
+	if g.capturePending {
+		g.capturePending = false
+		if compact {
+			log.Printf("Not capturing, the chart is hidden in compact mode")
+		} else {
+			defer g.captureChart(screen)
+		}
+	}
+
+	g.drawRefreshBar(screen)
+	if compact {
+		// Nothing to hit-test where the chart would be
+		g.chartArea, g.overviewArea = chartRect{}, chartRect{}
+		g.drawCoinList(screen)
+		return
+	}
+	g.chartArea = chartRect{chartLeft, chartTop, chartWidth, chartHeight}
+	g.overviewArea = overviewArea
+	g.drawCoinList(screen)
+	g.drawBanner(screen, chartLeft, chartTop, chartWidth)
+	if gridView {
+		g.drawCoinGrid(screen, g.chartArea)
+		return
+	}
+	if rsiPeriod > 0 {
+		g.drawRSIPane(screen, rsiArea, rsiPeriod)
+	}
+	if showMACD {
+		g.drawMACDPane(screen, macdArea)
+	}
+	if showOverview {
+		g.drawOverview(screen, overviewArea)
+	}
+
+	// Chart title
+	if g.SelectedCoinIndex >= 0 && g.SelectedCoinIndex < len(g.coinData) {
+		selectedCoin := g.coinData[g.SelectedCoinIndex]
+		chartTitle := fmt.Sprintf("%s %s Chart (%s) in %s", selectedCoin.Symbol, strings.Title(g.chartType), g.timeline, g.priceUnit(selectedCoin))
+		if compare := g.compareCoin(); compare != nil && g.chartType == "line" {
+			chartTitle = fmt.Sprintf("%s vs %s, %% change (%s)", selectedCoin.Symbol, compare.Symbol, g.timeline)
+		}
+		esset.DrawText(screen, chartTitle, 0, chartLeft+12, chartTop-28, g.fontFace, g.theme.TextSecondary)
+	}
+
+	// Vertical grid lines; the horizontal ones follow the price ticks, see drawPriceAxis
+	gridLines := g.config.GridLines
+	for i := 0; i <= gridLines; i++ {
+		// Vertical grid
+		gx := chartLeft + (chartWidth*float64(i))/float64(gridLines)
+		vector.StrokeLine(screen, float32(gx), float32(chartTop), float32(gx), float32(chartTop+chartHeight), 1, g.theme.Grid, false)
+	}
+
+	// Draw chart data, timed for the F3 overlay
+	start := time.Now()
+	points := g.drawChart(screen, chartRect{chartLeft, chartTop, chartWidth, chartHeight}, gridLines)
+	g.perf.record(time.Since(start), points)
+	g.drawReadout(screen)
+}
+
+// drawChart draws the selected coin's chart in area and returns how many
+// points it drew. Callers must hold g.mu.
+func (g *Game) drawChart(screen *ebiten.Image, area chartRect, gridLines int) int {
+	g.readout = ""
+	if g.SelectedCoinIndex < 0 || g.SelectedCoinIndex >= len(g.coinData) {
+		return 0
+	}
+	chartLeft, chartTop, chartWidth, chartHeight := area.left, area.top, area.width, area.height
+	selectedCoin := g.coinData[g.SelectedCoinIndex]
+	if selectedCoin.IsLoading {
+		g.drawSpinner(screen, chartLeft+chartWidth/2, chartTop+chartHeight/2, 12*g.deviceScale)
+	}
+	// Drawn last so it stays on top of either chart type
+	if age := g.staleFor(selectedCoin); age > 0 {
+		defer g.drawStaleBanner(screen, area, age)
+	}
+	if g.paused {
+		defer g.drawPausedBadge(screen, area)
+	}
+	if g.chartType == "candle" {
+		return g.drawCandleChart(screen, selectedCoin, area, gridLines)
+	}
+
+	history := g.chartPoints()
+	if compare := g.compareCoin(); compare != nil && len(history) > 0 {
+		g.drawCompareChart(screen, area, history, compare, gridLines)
+		return len(history)
+	}
+	var plotted []internal.PricePoint
+	if len(history) > 0 {
+		minPrice := history[0].Price
+		maxPrice := history[0].Price
+		for _, pp := range history {
+			if pp.Price < minPrice {
+				minPrice = pp.Price
+			}
+			if pp.Price > maxPrice {
+				maxPrice = pp.Price
+			}
+		}
+		high, low := maxPrice, minPrice
+		// Widen the range to the bands so they aren't clipped
+		var upper, lower []float64
+		if g.showBollinger {
+			upper, _, lower = bollinger(history, bollingerPeriod, bollingerMult)
+			for i := range upper {
+				if !math.IsNaN(upper[i]) {
+					maxPrice = math.Max(maxPrice, upper[i])
+					minPrice = math.Min(minPrice, lower[i])
+				}
+			}
+		}
+		if maxPrice == minPrice {
+			minPrice -= 0.001
+			maxPrice += 0.001
+		}
+		minPrice, maxPrice = g.yRange(selectedCoin.Symbol, minPrice, maxPrice)
+		g.drawPriceAxis(screen, area, minPrice, maxPrice, gridLines)
+		g.drawRangeLocked(screen, area, selectedCoin.Symbol)
+		if len(history) > 1 {
+			g.drawTimeAxis(screen, area, history[0].Timestamp, history[len(history)-1].Timestamp)
+		}
+		// Not enough history to fill the window yet
+		if window, ok := timelineDurations[g.timeline]; ok && time.Since(selectedCoin.PriceHistory[0].Timestamp) < window {
+			esset.DrawText(screen, "collecting data...", 0, chartLeft+chartWidth-140, chartTop+8, g.fontFace, g.theme.TextMuted)
+		}
+		plot := plotImage(screen, area)
+		if upper != nil {
+			g.drawBands(plot, area, upper, lower, minPrice, maxPrice)
+		}
+		g.drawHighLow(plot, area, high, low, minPrice, maxPrice)
+		// Draw chart line, in the up color where the price rose and the down color where it fell.
+		// Only a point per pixel column is drawn, the stats still use every point.
+		plotted = downsampleLTTB(history, g.plotPoints(area))
+		line := pointPrices(plotted)
+		if g.smoothAlpha > 0 {
+			// Keep the raw ticks as a faint underlay. The EMA runs over the
+			// whole timeline so zooming in doesn't reseed it.
+			faint := g.theme.TextMuted
+			faint.A = 90
+			g.strokeSeries(plot, area, line, minPrice, maxPrice, 1.5*float32(g.deviceScale), faint)
+			all := g.visiblePoints()
+			start, _ := g.viewRange(len(all))
+			smoothed := emaSeries(all, g.smoothAlpha)[start : start+len(history)]
+			smoothedPoints := make([]internal.PricePoint, len(history))
+			for i, pp := range history {
+				smoothedPoints[i] = internal.PricePoint{Price: smoothed[i], Timestamp: pp.Timestamp}
+			}
+			line = pointPrices(downsampleLTTB(smoothedPoints, g.plotPoints(area)))
+		}
+		g.strokeDirectional(plot, area, line, minPrice, maxPrice, g.lineWidth())
+		if g.config.PointMarkers {
+			g.drawMarkers(plot, area, line, minPrice, maxPrice)
+		}
+
+		if g.maPeriod > 0 {
+			ma := movingAverage(history, g.maPeriod)
+			g.strokeSeries(plot, area, ma, minPrice, maxPrice, 1.5*float32(g.deviceScale), g.theme.MA)
+		}
+
+		tooltip := g.drawCrosshair(screen, area, history, minPrice, maxPrice)
+		g.drawStatsBox(screen, area, history, tooltip)
+	}
+	return len(plotted)
+}
+
+// priceToY maps price to its height within min..max as a fraction from 0
+// (bottom) to 1 (top), on a log10 scale when log scale is on. Log scale needs
+// strictly positive prices, so a range touching zero falls back to linear,
+// and a non-positive price on a log axis is pinned to its bottom.
+func (g *Game) priceToY(price, min, max float64) float64 {
+	if g.logScale && min > 0 {
+		price = math.Max(price, min)
+		return (math.Log10(price) - math.Log10(min)) / (math.Log10(max) - math.Log10(min))
+	}
+	return (price - min) / (max - min)
+}
+
+// strokeSeries draws values as a line spread evenly across area, scaled to
+// minPrice..maxPrice. NaN values are skipped and break the line.
+func (g *Game) strokeSeries(screen *ebiten.Image, area chartRect, values []float64, minPrice, maxPrice float64, width float32, clr color.RGBA) {
+	path := &vector.Path{}
+	penDown := false
+	for i, v := range values {
+		if math.IsNaN(v) {
+			penDown = false
+			continue
+		}
+		x := area.left + (float64(i)/float64(max(len(values)-1, 1)))*area.width
+		y := area.top + area.height - g.priceToY(v, minPrice, maxPrice)*area.height
+		if penDown {
+			path.LineTo(float32(x), float32(y))
+		} else {
+			path.MoveTo(float32(x), float32(y))
+			penDown = true
+		}
+	}
+	g.drawPath(screen, path, width, clr)
+}
+
+// lineWidth is the configured price line width in physical pixels
+func (g *Game) lineWidth() float32 {
+	return float32(g.config.LineWidth * g.deviceScale)
+}
+
+// drawMarkers draws a dot at each of values, at the same positions as
+// strokeSeries. They're left out once points are denser than
+// maxMarkerDensity per pixel, where they'd merge into a solid band.
+func (g *Game) drawMarkers(screen *ebiten.Image, area chartRect, values []float64, minPrice, maxPrice float64) {
+	if float64(len(values))/area.width > maxMarkerDensity {
+		return
+	}
+	radius := float32(math.Max(g.config.LineWidth, 2) * g.deviceScale)
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		x := area.left + (float64(i)/float64(max(len(values)-1, 1)))*area.width
+		y := area.top + area.height - g.priceToY(v, minPrice, maxPrice)*area.height
+		vector.DrawFilledCircle(screen, float32(x), float32(y), radius, g.theme.Accent, true)
+	}
+}
+
+// strokeDirectional draws values like strokeSeries, but colors each segment
+// by whether the price went up or down. Runs of one color share a path and
+// each color is a single draw call, so long histories stay cheap. Flat
+// segments keep the color of the one before.
+func (g *Game) strokeDirectional(screen *ebiten.Image, area chartRect, values []float64, minPrice, maxPrice float64, width float32) {
+	upPath, downPath := &vector.Path{}, &vector.Path{}
+	var current *vector.Path
+	var prevX, prevY float32
+	prev := math.NaN()
+	for i, v := range values {
+		if math.IsNaN(v) {
+			prev, current = math.NaN(), nil
+			continue
+		}
+		x := float32(area.left + (float64(i)/float64(max(len(values)-1, 1)))*area.width)
+		y := float32(area.top + area.height - g.priceToY(v, minPrice, maxPrice)*area.height)
+
+		if !math.IsNaN(prev) {
+			path := current
+			switch {
+			case v > prev:
+				path = upPath
+			case v < prev:
+				path = downPath
+			case path == nil:
+				path = upPath
+			}
+			if path != current {
+				path.MoveTo(prevX, prevY)
+				current = path
+			}
+			path.LineTo(x, y)
+		}
+		prev, prevX, prevY = v, x, y
+	}
+	g.drawPath(screen, upPath, width, g.directionColor(true))
+	g.drawPath(screen, downPath, width, g.directionColor(false))
+}
+
+// drawBands draws the Bollinger Bands as two lines with a faint fill between
+// them. Leading NaN values, before there's a full period, are skipped.
+func (g *Game) drawBands(screen *ebiten.Image, area chartRect, upper, lower []float64, minPrice, maxPrice float64) {
+	start := 0
+	for start < len(upper) && math.IsNaN(upper[start]) {
+		start++
+	}
+	if start >= len(upper) {
+		return
+	}
+
+	point := func(i int, v float64) (float32, float32) {
+		x := area.left + (float64(i)/float64(max(len(upper)-1, 1)))*area.width
+		y := area.top + area.height - g.priceToY(v, minPrice, maxPrice)*area.height
+		return float32(x), float32(y)
+	}
+
+	// Upper band left to right, then the lower band back
+	fill := &vector.Path{}
+	fill.MoveTo(point(start, upper[start]))
+	for i := start + 1; i < len(upper); i++ {
+		fill.LineTo(point(i, upper[i]))
+	}
+	for i := len(lower) - 1; i >= start; i-- {
+		fill.LineTo(point(i, lower[i]))
+	}
+	fill.Close()
+
+	bandColor := g.theme.Band
+	vs, is := fill.AppendVerticesAndIndicesForFilling(nil, nil)
+	op := &ebiten.DrawTrianglesOptions{FillRule: ebiten.FillRuleNonZero}
+	op.ColorM.Scale(float64(bandColor.R)/255.0, float64(bandColor.G)/255.0, float64(bandColor.B)/255.0, 0.12)
+	screen.DrawTriangles(vs, is, g.solidColorImage, op)
+
+	width := float32(g.deviceScale)
+	g.strokeSeries(screen, area, upper, minPrice, maxPrice, width, bandColor)
+	g.strokeSeries(screen, area, lower, minPrice, maxPrice, width, bandColor)
+}
+
+// drawPath strokes path in a single draw call
+func (g *Game) drawPath(screen *ebiten.Image, path *vector.Path, width float32, clr color.RGBA) {
+	vs, is := path.AppendVerticesAndIndicesForStroke(nil, nil, &vector.StrokeOptions{
+		Width: width,
+	})
+	if len(is) == 0 {
+		return
+	}
+	op := &ebiten.DrawTrianglesOptions{}
+	op.ColorM.Scale(float64(clr.R)/255.0, float64(clr.G)/255.0, float64(clr.B)/255.0, float64(clr.A)/255.0)
+	screen.DrawTriangles(vs, is, g.solidColorImage, op)
+}
+
+// movingAverage returns the simple moving average of points over period. The
+// first period-1 values don't have enough data and are NaN.
+func movingAverage(points []internal.PricePoint, period int) []float64 {
+	result := make([]float64, len(points))
+	sum := 0.0
+	for i, pp := range points {
+		sum += pp.Price
+		if i >= period {
+			sum -= points[i-period].Price
+		}
+		if i < period-1 {
+			result[i] = math.NaN()
+		} else {
+			result[i] = sum / float64(period)
+		}
+	}
+	return result
+}
+
+// ema returns the exponential moving average of values over period, seeded
+// with the simple average of the first period values. Leading NaN values are
+// skipped, and values before the first full period are NaN.
+func ema(values []float64, period int) []float64 {
+	result := make([]float64, len(values))
+	start := 0
+	for start < len(values) && math.IsNaN(values[start]) {
+		start++
+	}
+	for i := range result {
+		result[i] = math.NaN()
+	}
+	if len(values)-start < period {
+		return result
+	}
+
+	seed := 0.0
+	for _, v := range values[start : start+period] {
+		seed += v
+	}
+	prev := seed / float64(period)
+	result[start+period-1] = prev
+
+	k := 2 / float64(period+1)
+	for i := start + period; i < len(values); i++ {
+		prev = values[i]*k + prev*(1-k)
+		result[i] = prev
+	}
+	return result
+}
+
+// emaSeries returns the exponentially smoothed prices of points, each value
+// alpha of the new price plus 1-alpha of the previous one. It's seeded with
+// the first price so, unlike ema, every value is set.
+func emaSeries(points []internal.PricePoint, alpha float64) []float64 {
+	result := make([]float64, len(points))
+	for i, pp := range points {
+		if i == 0 {
+			result[i] = pp.Price
+			continue
+		}
+		result[i] = alpha*pp.Price + (1-alpha)*result[i-1]
+	}
+	return result
+}
+
+// macd returns the MACD line (fast minus slow EMA of the prices), its signal
+// line EMA and the histogram of their difference. Values without enough data
+// are NaN.
+func macd(points []internal.PricePoint) (macdLine, signal, hist []float64) {
+	prices := make([]float64, len(points))
+	for i, pp := range points {
+		prices[i] = pp.Price
+	}
+	fast, slow := ema(prices, macdFast), ema(prices, macdSlow)
+
+	macdLine = make([]float64, len(points))
+	for i := range macdLine {
+		macdLine[i] = fast[i] - slow[i] // NaN while either is
+	}
+	signal = ema(macdLine, macdSignal)
+
+	hist = make([]float64, len(points))
+	for i := range hist {
+		hist[i] = macdLine[i] - signal[i]
+	}
+	return macdLine, signal, hist
+}
+
+// bollinger returns the Bollinger Bands of points: the simple moving average
+// over period and that average plus and minus mult population standard
+// deviations. The first period-1 values don't have enough data and are NaN.
+func bollinger(points []internal.PricePoint, period int, mult float64) (upper, mid, lower []float64) {
+	upper = make([]float64, len(points))
+	mid = movingAverage(points, period)
+	lower = make([]float64, len(points))
+	for i := range points {
+		if i < period-1 {
+			upper[i], lower[i] = math.NaN(), math.NaN()
+			continue
+		}
+		variance := 0.0
+		for _, pp := range points[i-period+1 : i+1] {
+			d := pp.Price - mid[i]
+			variance += d * d
+		}
+		stddev := math.Sqrt(variance / float64(period))
+		upper[i] = mid[i] + mult*stddev
+		lower[i] = mid[i] - mult*stddev
+	}
+	return upper, mid, lower
+}
+
+// rsi returns the Relative Strength Index of points over period using Wilder's
+// smoothing of the average gain and loss. The first period values don't have
+// enough data and are NaN.
+func rsi(points []internal.PricePoint, period int) []float64 {
+	result := make([]float64, len(points))
+	for i := range result {
+		result[i] = math.NaN()
+	}
+	if period <= 0 || len(points) <= period {
+		return result
+	}
+
+	avgGain, avgLoss := 0.0, 0.0
+	for i := 1; i < len(points); i++ {
+		change := points[i].Price - points[i-1].Price
+		gain, loss := math.Max(change, 0), math.Max(-change, 0)
+
+		if i <= period {
+			// Seed with a simple average of the first period changes
+			avgGain += gain / float64(period)
+			avgLoss += loss / float64(period)
+			if i < period {
+				continue
+			}
+		} else {
+			avgGain = (avgGain*float64(period-1) + gain) / float64(period)
+			avgLoss = (avgLoss*float64(period-1) + loss) / float64(period)
+		}
+
+		switch {
+		case avgLoss == 0 && avgGain == 0:
+			result[i] = 50
+		case avgLoss == 0:
+			result[i] = 100
+		default:
+			result[i] = 100 - 100/(1+avgGain/avgLoss)
+		}
+	}
+	return result
+}
+
+// drawRSIPane draws the selected coin's RSI below the main chart, with the
+// 30/70 oversold/overbought reference lines. Callers must hold g.mu.
+func (g *Game) drawRSIPane(screen *ebiten.Image, area chartRect, period int) {
+	vector.DrawFilledRect(screen, float32(area.left), float32(area.top), float32(area.width), float32(area.height), g.theme.Card, false)
+	vector.StrokeRect(screen, float32(area.left), float32(area.top), float32(area.width), float32(area.height), 2, g.theme.ControlActive, false)
+	esset.DrawText(screen, fmt.Sprintf("RSI %d", period), 0, area.left+8, area.top+6, g.fontFace, g.theme.TextSecondary)
+
+	for _, level := range []float64{30, 70} {
+		y := area.top + area.height - level/100*area.height
+		vector.StrokeLine(screen, float32(area.left), float32(y), float32(area.left+area.width), float32(y), 1, g.theme.Grid, false)
+		esset.DrawText(screen, fmt.Sprintf("%.0f", level), 0, area.left-30, y-8, g.fontFace, g.theme.TextSecondary)
+	}
+
+	history := g.chartPoints()
+	if len(history) <= period {
+		esset.DrawText(screen, "not enough data", 0, area.left+area.width/2-60, area.top+area.height/2-8, g.fontFace, g.theme.TextMuted)
+		return
+	}
+	// A 0-100 range never takes the log scale path in priceToY
+	g.strokeSeries(screen, area, rsi(history, period), 0, 100, 1.5*float32(g.deviceScale), g.theme.RSI)
+}
+
+// drawMACDPane draws the MACD and signal lines over the histogram in area,
+// scaled symmetrically around a zero line. Points line up with the chart's.
+// Callers must hold g.mu.
+func (g *Game) drawMACDPane(screen *ebiten.Image, area chartRect) {
+	vector.DrawFilledRect(screen, float32(area.left), float32(area.top), float32(area.width), float32(area.height), g.theme.Card, false)
+	vector.StrokeRect(screen, float32(area.left), float32(area.top), float32(area.width), float32(area.height), 2, g.theme.ControlActive, false)
+	esset.DrawText(screen, fmt.Sprintf("MACD %d %d %d", macdFast, macdSlow, macdSignal), 0, area.left+8, area.top+6, g.fontFace, g.theme.TextSecondary)
+
+	history := g.chartPoints()
+	if len(history) < macdSlow+macdSignal-1 {
+		esset.DrawText(screen, "not enough data", 0, area.left+area.width/2-60, area.top+area.height/2-8, g.fontFace, g.theme.TextMuted)
+		return
+	}
+	macdLine, signal, hist := macd(history)
+
+	limit := 0.0
+	for _, series := range [][]float64{macdLine, signal, hist} {
+		for _, v := range series {
+			if !math.IsNaN(v) {
+				limit = math.Max(limit, math.Abs(v))
+			}
+		}
+	}
+	if limit == 0 {
+		limit = 1
+	}
+	toY := func(v float64) float64 {
+		return area.top + area.height/2 - v/limit*area.height/2
+	}
+
+	zeroY := float32(toY(0))
+	vector.StrokeLine(screen, float32(area.left), zeroY, float32(area.left+area.width), zeroY, 1, g.theme.Grid, false)
+
+	n := len(hist)
+	barWidth := math.Max(area.width/float64(n)*0.7, 1)
+	for i, v := range hist {
+		if math.IsNaN(v) {
+			continue
+		}
+		x := area.left + (float64(i)/float64(max(n-1, 1)))*area.width
+		barColor := g.directionColor(v >= 0)
+		top, bottom := math.Min(toY(v), toY(0)), math.Max(toY(v), toY(0))
+		vector.DrawFilledRect(screen, float32(x-barWidth/2), float32(top), float32(barWidth), float32(max(bottom-top, 1)), barColor, false)
+	}
+
+	// A range straddling zero never takes the log scale path in priceToY
+	g.strokeSeries(screen, area, macdLine, -limit, limit, 1.5*float32(g.deviceScale), g.theme.Accent)
+	g.strokeSeries(screen, area, signal, -limit, limit, 1.5*float32(g.deviceScale), g.theme.MA)
+}
+
+// nearestPointIndex maps screen x back to the closest of n points plotted
+// evenly across area, the inverse of the x mapping used for the line
+func nearestPointIndex(x float64, area chartRect, n int) int {
+	if n <= 1 {
+		return 0
+	}
+	i := int(math.Round((x - area.left) / area.width * float64(n-1)))
+	return max(0, min(i, n-1))
+}
+
+// drawCrosshair follows the cursor over the chart, snapping the vertical line
+// to the nearest point and showing its price and time in a tooltip. It
+// returns the tooltip's bounds, empty when the cursor is off the chart.
+func (g *Game) drawCrosshair(screen *ebiten.Image, area chartRect, history []internal.PricePoint, minPrice, maxPrice float64) chartRect {
+	mx, my := ebiten.CursorPosition()
+	cx, cy := float64(mx), float64(my)
+	if cx < area.left || cx > area.left+area.width || cy < area.top || cy > area.top+area.height {
+		return chartRect{}
+	}
+
+	index := nearestPointIndex(cx, area, len(history))
+	point := history[index]
+	g.readout = g.pointReadout(point)
+	px := area.left + (float64(index)/float64(max(len(history)-1, 1)))*area.width
+	py := area.top + area.height - g.priceToY(point.Price, minPrice, maxPrice)*area.height
+
+	lineColor := g.theme.TextMuted
+	vector.StrokeLine(screen, float32(px), float32(area.top), float32(px), float32(area.top+area.height), 1, lineColor, false)
+	vector.StrokeLine(screen, float32(area.left), float32(cy), float32(area.left+area.width), float32(cy), 1, lineColor, false)
+	vector.DrawFilledCircle(screen, float32(px), float32(py), 3, g.theme.Accent, false)
+
+	priceLabel := g.formatSelectedPrice(point.Price)
+	timeLabel := point.Timestamp.Format("15:04:05")
+	priceW, lineH := text.Measure(priceLabel, g.fontFace, 0)
+	timeW, _ := text.Measure(timeLabel, g.fontFace, 0)
+	boxW := math.Max(priceW, timeW) + 16
+	boxH := lineH*2 + 16
+
+	// Keep the tooltip inside the chart, flipping it to the other side of the cursor near the edges
+	boxX := px + 10
+	if boxX+boxW > area.left+area.width {
+		boxX = px - 10 - boxW
+	}
+	boxY := cy - boxH - 10
+	if boxY < area.top {
+		boxY = cy + 10
+	}
+	boxX = math.Max(area.left, math.Min(boxX, area.left+area.width-boxW))
+	boxY = math.Max(area.top, math.Min(boxY, area.top+area.height-boxH))
+
+	vector.DrawFilledRect(screen, float32(boxX), float32(boxY), float32(boxW), float32(boxH), g.theme.Topbar, false)
+	vector.StrokeRect(screen, float32(boxX), float32(boxY), float32(boxW), float32(boxH), 1, g.theme.Border, false)
+	esset.DrawText(screen, priceLabel, 0, boxX+8, boxY+6, g.fontFace, g.theme.TextPrimary)
+	esset.DrawText(screen, timeLabel, 0, boxX+8, boxY+10+lineH, g.fontFace, g.theme.TextSecondary)
+	return chartRect{boxX, boxY, boxW, boxH}
+}
+
+// windowStats returns the lowest, highest, average and latest price of
+// points, which must not be empty
+func windowStats(points []internal.PricePoint) (min, max, avg, last float64) {
+	min, max = points[0].Price, points[0].Price
+	sum := 0.0
+	for _, pp := range points {
+		min = math.Min(min, pp.Price)
+		max = math.Max(max, pp.Price)
+		sum += pp.Price
+	}
+	return min, max, sum / float64(len(points)), points[len(points)-1].Price
+}
+
+// drawStatsBox shows windowStats of the visible points in the chart's top left
+// corner, or the bottom left while the crosshair tooltip is in the way.
+// Callers must hold g.mu.
+func (g *Game) drawStatsBox(screen *ebiten.Image, area chartRect, history []internal.PricePoint, tooltip chartRect) {
+	low, high, avg, last := windowStats(history)
+	lines := []string{
+		"Min  " + g.formatSelectedPrice(low),
+		"Max  " + g.formatSelectedPrice(high),
+		"Avg  " + g.formatSelectedPrice(avg),
+		"Last " + g.formatSelectedPrice(last),
+	}
+
+	boxW, lineH := 0.0, 0.0
+	for _, line := range lines {
+		w, h := text.Measure(line, g.fontFace, 0)
+		boxW, lineH = math.Max(boxW, w), h
+	}
+	boxW += 16
+	boxH := float64(len(lines))*(lineH+4) + 12
+
+	box := chartRect{area.left + 8, area.top + 8, boxW, boxH}
+	if box.overlaps(tooltip) {
+		box.top = area.top + area.height - boxH - 8
+	}
+
+	vector.DrawFilledRect(screen, float32(box.left), float32(box.top), float32(box.width), float32(box.height), g.theme.Topbar, false)
+	vector.StrokeRect(screen, float32(box.left), float32(box.top), float32(box.width), float32(box.height), 1, g.theme.Border, false)
+	for i, line := range lines {
+		esset.DrawText(screen, line, 0, box.left+8, box.top+6+float64(i)*(lineH+4), g.fontFace, g.theme.TextSecondary)
+	}
+}
+
+// visiblePoints returns the selected coin's history that falls inside the
+// selected timeline window. Callers must hold g.mu.
+func (g *Game) visiblePoints() []internal.PricePoint {
+	if g.SelectedCoinIndex < 0 || g.SelectedCoinIndex >= len(g.coinData) {
+		return nil
+	}
+	return g.timelinePoints(g.coinData[g.SelectedCoinIndex])
+}
+
+// timelinePoints returns coin's history that falls inside the selected
+// timeline window. Callers must hold g.mu.
+func (g *Game) timelinePoints(coin *internal.CoinInfo) []internal.PricePoint {
+	history := coin.PriceHistory
+	window, ok := timelineDurations[g.timeline]
+	if !ok {
+		return history
+	}
+	cutoff := time.Now().Add(-window)
+	start := sort.Search(len(history), func(i int) bool {
+		return !history[i].Timestamp.Before(cutoff)
+	})
+	return history[start:]
+}
+
+// chartPoints returns the part of visiblePoints inside the pan/zoom window.
+// Callers must hold g.mu.
+func (g *Game) chartPoints() []internal.PricePoint {
+	points := g.visiblePoints()
+	start, end := g.viewRange(len(points))
+	return points[start:end]
+}
+
+// viewRange maps the pan/zoom window onto n points, keeping at least
+// minViewPoints of them. Callers must hold g.mu.
+func (g *Game) viewRange(n int) (start, end int) {
+	if g.brushEnd <= g.brushStart || (g.brushStart <= 0 && g.brushEnd >= 1) {
+		return 0, n
+	}
+	start = min(max(int(math.Round(g.brushStart*float64(n))), 0), n)
+	end = min(max(int(math.Round(g.brushEnd*float64(n))), start), n)
+	if end-start < minViewPoints {
+		end = min(start+minViewPoints, n)
+		start = max(end-minViewPoints, 0)
+	}
+	return start, end
+}
+
+// setView sets the pan/zoom window to points start..end of n. Callers must
+// hold g.mu.
+func (g *Game) setView(start, end, n int) {
+	g.brushStart, g.brushEnd = float64(start)/float64(n), float64(end)/float64(n)
+}
+
+// resetView snaps the chart back to the full range. Callers must hold g.mu.
+func (g *Game) resetView() {
+	g.brushStart, g.brushEnd = 0, 0
+	g.brushDrag = brushNone
+	g.dragging = false
+}
+
+// handleChartInput zooms the line chart around the cursor with the mouse
+// wheel and pans it by dragging it or the overview brush. Double-click or R
+// resets the view.
+func (g *Game) handleChartInput() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.chartType != "line" {
+		return
+	}
+	mx, my := ebiten.CursorPosition()
+	if g.handleBrushInput(mx, my) {
+		return
+	}
+	area := g.chartArea
+	inChart := float64(mx) >= area.left && float64(mx) <= area.left+area.width &&
+		float64(my) >= area.top && float64(my) <= area.top+area.height
+	n := len(g.visiblePoints())
+	start, end := g.viewRange(n)
+	width := end - start
+
+	if _, dy := ebiten.Wheel(); dy != 0 && inChart && n > 1 {
+		// Keep the point under the cursor in place while zooming
+		frac := (float64(mx) - area.left) / area.width
+		anchor := float64(start) + frac*float64(width)
+		newWidth := float64(width) * 1.25
+		if dy > 0 {
+			newWidth = float64(width) * 0.8
+		}
+		newWidth = math.Max(newWidth, minViewPoints)
+		if int(newWidth) >= n {
+			g.resetView()
+			return
+		}
+		newStart := int(math.Round(anchor - frac*newWidth))
+		newStart = max(0, min(newStart, n-int(newWidth)))
+		g.setView(newStart, newStart+int(newWidth), n)
+		return
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && inChart {
+		if time.Since(g.lastChartClick) < doubleClickInterval {
+			g.resetView()
+			g.lastChartClick = time.Time{}
+			return
+		}
+		g.lastChartClick = time.Now()
+		g.dragging = true
+		g.dragLastX = mx
+		g.dragRemainder = 0
+		return
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		g.dragging = false
+		return
+	}
+	if g.dragging && width < n {
+		// Dragging right moves the window back in time
+		g.dragRemainder -= float64(mx-g.dragLastX) / area.width * float64(width)
+		g.dragLastX = mx
+		shift := int(g.dragRemainder)
+		g.dragRemainder -= float64(shift)
+		newStart := max(0, min(start+shift, n-width))
+		g.setView(newStart, newStart+width, n)
+	}
+}
+
+// formatSelectedPrice formats a price of the selected coin with its precision
+// and quote asset. Callers must hold g.mu.
+func (g *Game) formatSelectedPrice(price float64) string {
+	if g.SelectedCoinIndex < 0 || g.SelectedCoinIndex >= len(g.coinData) {
+		return formatPrice(price, internal.PricePrecision())
+	}
+	coin := g.coinData[g.SelectedCoinIndex]
+	return formatQuoted(price, coin.Decimals(), g.quoteAsset(coin))
+}
+
+// drawPriceAxis draws a horizontal grid line at each round price tick in
+// minPrice..maxPrice and labels it with the price, with as many decimals as
+// the range needs to tell the ticks apart. Callers must hold g.mu.
+func (g *Game) drawPriceAxis(screen *ebiten.Image, area chartRect, minPrice, maxPrice float64, gridLines int) {
+	if g.SelectedCoinIndex < 0 || g.SelectedCoinIndex >= len(g.coinData) {
+		return
+	}
+	coin := g.coinData[g.SelectedCoinIndex]
+	quote := g.quoteAsset(coin)
+
+	// The labels show converted prices, so size the decimals for their range
+	priceRange := maxPrice - minPrice
+	if quote == baseQuote || quote == "" {
+		_, rate := displayCurrency()
+		priceRange *= rate
+	}
+	decimals := axisDecimals(priceRange)
+	// More decimals than the exchange's tick size only adds noise
+	if coin.PriceDecimals != nil {
+		decimals = min(decimals, *coin.PriceDecimals)
+	}
+
+	g.drawValueAxis(screen, area, minPrice, maxPrice, gridLines, func(price float64) string {
+		return formatQuoted(price, decimals, quote)
+	})
+	if g.config.AxisSide != axisLeft {
+		g.drawPriceTag(screen, area, coin, minPrice, maxPrice, decimals, quote)
+	}
+}
+
+// drawPriceTag draws the latest price in a tag on the right axis, filled
+// with the color of its last move and clamped to the chart's height
+func (g *Game) drawPriceTag(screen *ebiten.Image, area chartRect, coin *internal.CoinInfo, minPrice, maxPrice float64, decimals int, quote string) {
+	latest := g.latestPrice()
+	if latest == 0 {
+		return
+	}
+	label := formatQuoted(latest, decimals, quote)
+	labelW, labelH := text.Measure(label, g.fontFace, 0)
+	tagH := labelH + 4*g.deviceScale
+	y := area.top + area.height - g.priceToY(latest, minPrice, maxPrice)*area.height - tagH/2
+	y = math.Min(math.Max(y, area.top), area.top+area.height-tagH)
+
+	fill := g.theme.Accent
+	switch priceDirection(coin) {
+	case 1:
+		fill = g.directionColor(true)
+	case -1:
+		fill = g.directionColor(false)
+	}
+	x := area.left + area.width + 2*g.deviceScale
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(labelW+8*g.deviceScale), float32(tagH), fill, false)
+	esset.DrawText(screen, label, 0, x+4*g.deviceScale, y+2*g.deviceScale, g.fontFace, g.theme.Background)
+}
+
+// Sides the price axis labels can go on, the axis_side setting
+const (
+	axisLeft  = "left"
+	axisRight = "right"
+	axisBoth  = "both"
+)
+
+// axisLabelWidth is the room kept right of the chart for right axis labels
+const axisLabelWidth = 64.0
+
+// axisDecimals is how many decimals axis labels need for a visible range of
+// priceRange: none for ranges in the hundreds, up to 8 for tiny ones like a
+// stablecoin moving around 1.0001
+func axisDecimals(priceRange float64) int {
+	if priceRange <= 0 || math.IsNaN(priceRange) || math.IsInf(priceRange, 0) {
+		return internal.PricePrecision()
+	}
+	// Ticks are about a tenth of the range apart, two digits below its
+	// leading one keeps neighbouring labels distinct
+	return min(max(int(math.Floor(-math.Log10(priceRange)))+2, 0), 8)
+}
+
+// drawValueAxis is drawPriceAxis with the labels formatted by label, drawn
+// on the side or sides the axis_side setting picks
+func (g *Game) drawValueAxis(screen *ebiten.Image, area chartRect, low, high float64, gridLines int, label func(float64) string) {
+	for _, tick := range niceTicks(low, high, gridLines+1) {
+		gy := area.top + area.height - g.priceToY(tick, low, high)*area.height
+		vector.StrokeLine(screen, float32(area.left), float32(gy), float32(area.left+area.width), float32(gy), 1, g.theme.Grid, false)
+		if g.config.AxisSide != axisRight {
+			esset.DrawText(screen, label(tick), 0, area.left-60, gy-8, g.fontFace, g.theme.TextSecondary)
+		}
+		if g.config.AxisSide != axisLeft {
+			esset.DrawText(screen, label(tick), 0, area.left+area.width+6*g.deviceScale, gy-8, g.fontFace, g.theme.TextSecondary)
+		}
+	}
+}
+
+// niceTicks returns round values from min to max, about count of them, spaced
+// by a step of 1, 2 or 5 times a power of ten
+func niceTicks(min, max float64, count int) []float64 {
+	if count < 2 || !(max > min) {
+		return []float64{min}
+	}
+	step := niceNum((max - min) / float64(count-1))
+	start := math.Ceil(min/step) * step
+
+	var ticks []float64
+	for i := 0; ; i++ {
+		// Multiplying rather than accumulating keeps float error from building up
+		tick := start + float64(i)*step
+		if tick > max+step*1e-9 {
+			break
+		}
+		ticks = append(ticks, tick)
+	}
+	return ticks
+}
+
+// niceNum rounds x to the nearest 1, 2, 5 or 10 times a power of ten
+func niceNum(x float64) float64 {
+	exp := math.Floor(math.Log10(x))
+	fraction := x / math.Pow(10, exp)
+
+	nice := 10.0
+	switch {
+	case fraction < 1.5:
+		nice = 1
+	case fraction < 3:
+		nice = 2
+	case fraction < 7:
+		nice = 5
+	}
+	return nice * math.Pow(10, exp)
+}
+
+// drawTimeAxis labels the start and end of the chart's time range
+func (g *Game) drawTimeAxis(screen *ebiten.Image, area chartRect, start, end time.Time) {
+	esset.DrawText(screen, start.Format("15:04"), 0, area.left, area.top+area.height+8, g.fontFace, g.theme.TextSecondary)
+	esset.DrawText(screen, end.Format("15:04"), 0, area.left+area.width-40, area.top+area.height+8, g.fontFace, g.theme.TextSecondary)
+}
+
+// klinesFor returns the cached klines for symbol on timeline, kicking off a
+// background refresh when they are missing or stale. Callers must hold g.mu.
+func (g *Game) klinesFor(symbol, timeline string) []internal.Kline {
+	params, ok := klineParams[timeline]
+	if !ok {
+		return nil
+	}
+
+	if g.klineCache == nil {
+		g.klineCache = make(map[string]*klineEntry)
+	}
+	// kline_limit trades the window each candle covers for fewer requests
+	limit := params.limit
+	if g.config.KlineLimit > 0 {
+		limit = g.config.KlineLimit
+	}
+
+	key := symbol + "@" + timeline
+	entry := g.klineCache[key]
+	if entry == nil {
+		entry = &klineEntry{}
+		g.klineCache[key] = entry
+	}
+	// Switching symbol or timeline drops the fetch for the previous one
+	if key != g.klineKey && g.klineCancel != nil {
+		g.klineCancel()
+	}
+	g.klineKey = key
+
+	if !entry.loading && time.Since(entry.fetchedAt) >= klineRefreshInterval {
+		entry.loading = true
+		ctx, cancel := context.WithCancel(g.ctx)
+		g.klineCancel = cancel
+		go func() {
+			defer cancel()
+			klines, err := g.source.GetKlines(ctx, symbol, params.interval, limit)
+
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			entry.loading = false
+			// Cancelled fetches are retried once the klines are shown again
+			if ctx.Err() != nil {
+				return
+			}
+			entry.fetchedAt = time.Now()
+			entry.err = err
+			if err != nil {
+				log.Printf("Could not get klines [%s %s]: %v", symbol, timeline, err)
+				return
+			}
+			entry.klines = klines
+		}()
+	}
+
+	return entry.klines
+}
+
+// minCandleAlpha is the body opacity of a candle without volume when
+// volume_shading is on
+const minCandleAlpha = 0.3
+
+// toggleVolumeShading turns volume shading of candle bodies on or off and
+// saves the choice
+func (g *Game) toggleVolumeShading() {
+	g.config.VolumeShading = !g.config.VolumeShading
+	if err := saveConfig(g.config, configFilename); err != nil {
+		log.Printf("Error saving config: %v", err)
+	}
+}
+
+// drawCandleChart draws OHLC candles for coin on the selected timeline and
+// returns how many it drew
+func (g *Game) drawCandleChart(screen *ebiten.Image, coin *internal.CoinInfo, area chartRect, gridLines int) int {
+	klines := g.klinesFor(coin.Symbol, g.timeline)
+	if len(klines) == 0 {
+		// Without klines from the source, build candles from the polled history
+		if params, ok := klineParams[g.timeline]; ok {
+			klines = aggregateOHLC(g.visiblePoints(), params.bucket)
+		}
+	}
+	if len(klines) == 0 {
+		message := "Loading candles..."
+		if entry := g.klineCache[coin.Symbol+"@"+g.timeline]; entry != nil && errors.Is(entry.err, internal.ErrUnsupported) {
+			message = fmt.Sprintf("Candles are not available from %s", g.source.Name())
+		}
+		esset.DrawText(screen, message, 0, area.left+12, area.top+12, g.fontFace, g.theme.TextSecondary)
+		return 0
+	}
+	// Candles thinner than minCandleWidth are merged with their neighbours
+	klines = mergeKlines(klines, max(int(area.width/(minCandleWidth*g.deviceScale)), 1))
+
+	minPrice := klines[0].Low
+	maxPrice := klines[0].High
+	for _, k := range klines {
+		minPrice = math.Min(minPrice, k.Low)
+		maxPrice = math.Max(maxPrice, k.High)
+	}
+	if maxPrice == minPrice {
+		minPrice -= 0.001
+		maxPrice += 0.001
+	}
+	minPrice, maxPrice = g.yRange(coin.Symbol, minPrice, maxPrice)
+
+	g.drawPriceAxis(screen, area, minPrice, maxPrice, gridLines)
+	g.drawTimeAxis(screen, area, klines[0].OpenTime, klines[len(klines)-1].OpenTime)
+	g.drawRangeLocked(screen, area, coin.Symbol)
+	plot := plotImage(screen, area)
+
+	toY := func(price float64) float32 {
+		return float32(area.top + area.height - g.priceToY(price, minPrice, maxPrice)*area.height)
+	}
+
+	// Bodies fade with volume relative to the busiest candle. Candles built
+	// from polled history have no volume and stay solid.
+	maxVolume := 0.0
+	if g.config.VolumeShading {
+		for _, k := range klines {
+			maxVolume = math.Max(maxVolume, k.Volume)
+		}
+	}
+
+	candleW := area.width / float64(len(klines))
+	for i, k := range klines {
+		candleColor := g.directionColor(k.Close >= k.Open)
+		var bodyColor color.Color = candleColor
+		if maxVolume > 0 {
+			alpha := minCandleAlpha + (1-minCandleAlpha)*k.Volume/maxVolume
+			bodyColor = color.NRGBA{candleColor.R, candleColor.G, candleColor.B, uint8(alpha * 255)}
+		}
+
+		x := area.left + float64(i)*candleW
+		centerX := float32(x + candleW/2)
+		vector.StrokeLine(plot, centerX, toY(k.High), centerX, toY(k.Low), 1, candleColor, false)
+
+		bodyTop := toY(math.Max(k.Open, k.Close))
+		bodyHeight := max(toY(math.Min(k.Open, k.Close))-bodyTop, 1)
+		vector.DrawFilledRect(plot, float32(x+candleW*0.15), bodyTop, float32(candleW*0.7), bodyHeight, bodyColor, false)
+	}
+	if i := hoveredCandle(area, len(klines)); i >= 0 {
+		g.readout = g.candleReadout(klines[i])
+	}
+	return len(klines)
+}
+
+// aggregateOHLC groups points into candles of bucket length, aligned to
+// multiples of bucket, taking the first, highest, lowest and last price of
+// each. Buckets without points are left out. Volume isn't known and is 0.
+func aggregateOHLC(points []internal.PricePoint, bucket time.Duration) []internal.Kline {
+	var klines []internal.Kline
+	for _, pp := range points {
+		openTime := pp.Timestamp.Truncate(bucket)
+		if n := len(klines); n > 0 && klines[n-1].OpenTime.Equal(openTime) {
+			k := &klines[n-1]
+			k.High = math.Max(k.High, pp.Price)
+			k.Low = math.Min(k.Low, pp.Price)
+			k.Close = pp.Price
+			continue
+		}
+		klines = append(klines, internal.Kline{
+			OpenTime: openTime,
+			Open:     pp.Price,
+			High:     pp.Price,
+			Low:      pp.Price,
+			Close:    pp.Price,
+		})
+	}
+	return klines
+}
+
+func (g *Game) Update() error {
+	// Closing the window saves like a SIGINT does, while the window's
+	// geometry can still be read
+	if ebiten.IsWindowBeingClosed() {
+		g.Shutdown()
+		return ebiten.Termination
+	}
+
+	// Rebuild fonts and layout when the window moves to a monitor with
+	// another scale factor
+	if scale := ebiten.Monitor().DeviceScaleFactor(); scale != g.deviceScale {
+		log.Printf("Device scale changed from %.2f to %.2f", g.deviceScale, scale)
+		if err := g.rebuildLayout(scale); err != nil {
+			log.Printf("Could not rebuild layout: %v", err)
+		}
+	}
+
+	// Prefer the live stream, runPolling takes over while it's down
+	g.mu.Lock()
+	stream, paused := g.stream, g.paused
+	pendingGlyphs := g.pendingGlyphs
+	g.pendingGlyphs = nil
+	topbarStale := g.topbarStale
+	g.mu.Unlock()
+	if topbarStale {
+		g.layoutTopbar()
+	}
+	switch {
+	case stream == nil || !stream.Connected():
+	case paused:
+		// Drop trades while paused so the buffer doesn't replay them on resume
+		drainStream(stream)
+	default:
+		g.consumeStream(stream)
+	}
+	g.cacheGlyphs(pendingGlyphs...)
+
+	g.handleShortcuts(true)
+
+	if !paused && time.Since(g.lastTickerUpdate) >= internal.UpdateInterval() {
+		g.lastTickerUpdate = time.Now()
+		g.refreshTickers()
+	}
+
+	// The help overlay closes on any key or click
+	if g.showHelp {
+		if len(inpututil.AppendJustPressedKeys(nil)) > 0 || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			g.showHelp = false
+		}
+		return nil
+	}
+
+	// B toggles big number mode; any click or Escape also leaves it
+	if g.bigNumberMode {
+		if inpututil.IsKeyJustPressed(ebiten.KeyB) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) ||
+			inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			g.bigNumberMode = false
+		}
+		return nil
+	}
+	// The settings panel is modal and takes all input while open
+	if g.settings.IsOpen {
+		g.settings.Update(g)
+		return nil
+	}
+	if g.contextMenu != nil {
+		g.handleContextMenuInput()
+		return nil
+	}
+	// While typing, or while a dropdown is open, keys go to the input or
+	// the dropdown's filter instead
+	if !g.textInputFocused() && g.activeDropdown == nil && g.handleShortcuts(false) {
+		return nil
+	}
+
+	if g.handleTopbarInput() {
+		return nil
+	}
+
+	// Only handle coin selection and chart pan/zoom if no dropdown is active
+	if g.activeDropdown == nil {
+		g.mu.Lock()
+		gridView, compact := g.gridView, g.config.CompactMode
+		if gridView && !compact && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			mx, my := ebiten.CursorPosition()
+			g.selectTile(mx, my)
+		}
+		g.mu.Unlock()
+		if !gridView && !compact {
+			g.handleChartInput()
+		}
+
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			mx, my := ebiten.CursorPosition()
+
+			// Shift-click overlays the coin on the chart instead of selecting it
+			g.mu.Lock()
+			i := g.coinRowAt(mx, my)
+			if i >= 0 && retryable(g.coinData[i]) && image.Pt(mx, my).In(g.retryButton(i)) {
+				g.retryCoin(g.coinData[i])
+			} else if i >= 0 && ebiten.IsKeyPressed(ebiten.KeyShift) {
+				g.toggleCompare(i)
+			} else if i >= 0 {
+				// Selecting waits for the release, as the press may start a drag
+				g.rowDrag = &rowDrag{index: i, startY: my, y: my}
+			}
+			g.mu.Unlock()
+		}
+		g.handleRowDrag()
+
+		// Right-clicking a coin in the list opens its context menu
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+			mx, my := ebiten.CursorPosition()
+
+			g.mu.Lock()
+			if i := g.coinRowAt(mx, my); i >= 0 {
+				g.openCoinMenu(g.coinData[i], mx, my)
+			}
+			g.mu.Unlock()
+		}
+	} else {
+		// Opening a dropdown drops any row drag in progress
+		g.mu.Lock()
+		g.rowDrag = nil
+		g.mu.Unlock()
+	}
+
+	return nil
+}
+
+// coinRowAt returns the index of the coin whose list row is at mx, my, or -1.
+// Callers must hold g.mu.
+func (g *Game) coinRowAt(mx, my int) int {
+	for i := range g.coinData {
+		if image.Pt(mx, my).In(g.coinRowBounds(i)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// coinListTop is where the first coin list row's text is drawn, below the
+// topbar
+func (g *Game) coinListTop() float64 {
+	return g.topbarHeight + 10*g.deviceScale
+}
+
+// coinRowBounds is the list row of the coin at index i across the whole
+// list column, the area drawn as hovered and hit by clicks. Rows start a
+// little above their text and tile the list without gaps.
+func (g *Game) coinRowBounds(i int) image.Rectangle {
+	top := g.coinListTop() + float64(i)*g.physicalLineHeight - coinRowInset*g.deviceScale
+	return image.Rect(0, int(top), int(coinListBaseWidth*g.deviceScale), int(top+g.physicalLineHeight))
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	return outsideWidth, outsideHeight
+}
+
+// Options are command line overrides of the config and saved state
+type Options struct {
+	Interval time.Duration // price update interval, overrides update_interval_ms when set
+	Symbols  []string      // tracked symbols when there's no saved state yet
+	Reset    bool          // use Symbols even over a saved list
+	Verbose  bool          // log fetch and save metrics as JSON records
+	// MetricsAddr is where a headless game serves /healthz, /prices and
+	// /metrics over HTTP, e.g. ":8080". Empty turns the server off.
+	MetricsAddr string
+	// Source replaces the configured price source, e.g. for -replay. State
+	// isn't saved while it's set, so a demo doesn't overwrite the real one.
+	Source internal.PriceSource
+}
+
+// newGame loads the config and saved state into a Game with everything but
+// the window and fonts, which only NewGame sets up
+func newGame(opts Options) (*Game, AppData) {
+	cfg, err := loadConfig(configFilename)
+	if err != nil {
+		log.Printf("Error loading config: %v. Using defaults.", err)
+	}
+	cfg.apply()
+	if opts.Interval > 0 {
+		internal.SetUpdateInterval(opts.Interval)
+	}
+
+	loadedData, err := loadData(stateFilename)
+	if err != nil {
+		log.Printf("Error loading state: %v. Starting with empty state.", err)
+	}
+	if len(opts.Symbols) > 0 && (opts.Reset || (loadedData.Symbols == nil && len(loadedData.CoinData) == 0)) {
+		loadedData.Symbols = opts.Symbols
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	source := newPriceSource(cfg.Source)
+	if opts.Source != nil {
+		source = opts.Source
+	}
+	g := &Game{
+		source:            source,
+		noSave:            opts.Source != nil,
+		config:            cfg,
+		theme:             themeByName(cfg.Theme),
+		settings:          newSettingsPanel(),
+		currency:          currencies[0],
+		currencyChanged:   make(chan struct{}, 1),
+		ctx:               ctx,
+		cancel:            cancel,
+		coinData:          initCoinData(loadedData),
+		alerts:            loadedData.Alerts,
+		savedWindow:       loadedData.Window,
+		lastUpdateTime:    time.Now().Add(-internal.UpdateInterval()),
+		SelectedCoinIndex: 0,
+		CompareCoinIndex:  -1,
+		yRangeMode:        yRangeFit,
+		metrics:           newMetrics(opts.Verbose),
+	}
+	return g, loadedData
+}
+
+// NewGame loads the config and saved state and sets up the window, building
+// its fonts from fontData
+func NewGame(fontData []byte, opts Options) (*Game, error) {
+	g, loadedData := newGame(opts)
+
+	g.fontData = fontData
+	g.initTopbar() // Initialize topbar
+	if err := g.rebuildLayout(ebiten.Monitor().DeviceScaleFactor()); err != nil {
+		return nil, err
+	}
+
+	restoreWindow(loadedData.Window)
+	ebiten.SetWindowFloating(g.config.AlwaysOnTop)
+	if g.config.CompactMode {
+		g.mu.Lock()
+		g.enterCompactWindow()
+		g.mu.Unlock()
+	}
+
+	fmt.Println("Glyph caching...")
+	g.cacheGlyphs(append([]string{glyphsToPreload}, g.coinSymbols()...)...)
+	fmt.Println("Glyph caching done.")
+
+	g.sortCoins()
+
+	// Alerts still show without sound if audio can't be set up
+	if err := g.initSound(); err != nil {
+		log.Printf("Alert sounds disabled: %v", err)
+	}
+
+	g.startStream()
+	g.pollDone = make(chan struct{})
+	go g.runPolling()
+	go g.fetchDecimals(g.coinSnapshot())
+	go g.loadSymbolInfo()
+	go g.runCurrencyRates()
+	go g.runAutosave(time.Duration(g.config.AutosaveIntervalS) * time.Second)
+
+	if len(g.coinData) > 0 && g.SelectedCoinIndex == -1 {
+		g.SelectedCoinIndex = 0
+	} else if len(g.coinData) == 0 {
+		g.SelectedCoinIndex = -1
+	}
+
+	return g, nil
+}
+
+// Shutdown stops all background fetching and saves the app state
+func (g *Game) Shutdown() {
+	g.cancel()
+	if g.pollDone != nil {
+		<-g.pollDone // don't save while a round is still applying prices
+	}
+	if g.serverDone != nil {
+		<-g.serverDone
+	}
+
+	if err := g.save(true); err != nil {
+		log.Printf("Error saving state on exit: %v", err)
+	}
+}
+
+// runPolling fetches prices over REST every UpdateInterval while the stream
+// is down, off the render goroutine so a slow network never stalls Update
+func (g *Game) runPolling() {
+	defer close(g.pollDone)
+
+	for {
+		g.mu.Lock()
+		stream, paused := g.stream, g.paused
+		g.mu.Unlock()
+
+		// While paused just keep ticking, so unpausing fetches on the next
+		// interval instead of catching up on the missed rounds
+		interval := internal.UpdateInterval()
+		if !paused && (stream == nil || !stream.Connected()) {
+			interval = g.recordPollResult(g.updateAllPrices())
+		}
+		next := time.NewTimer(interval)
+
+		select {
+		case <-g.ctx.Done():
+			next.Stop()
+			return
+		case <-next.C:
+		}
+	}
+}
+
+// recordPollResult updates the failure streak with the outcome of a polling
+// round and returns how long to wait before the next one
+func (g *Game) recordPollResult(ok bool) time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// applyPrice resets the streak on success, from polling or the stream
+	interval := internal.UpdateInterval()
+	if !ok {
+		g.failStreak++
+		if g.failStreak >= pollFailureThreshold {
+			interval = min(interval<<min(g.failStreak-pollFailureThreshold+1, 16), maxPollBackoff)
+			log.Printf("All price fetches failing (%d rounds), retrying in %s", g.failStreak, interval)
+		}
+	}
+	g.lastPoll = time.Now()
+	g.nextPoll = g.lastPoll.Add(interval)
+	return interval
+}
+
+// runAutosave saves the state every interval while it has unsaved changes,
+// so a crash or kill loses at most one interval
+func (g *Game) runAutosave(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := g.save(false); err != nil {
+				log.Printf("Error autosaving state: %v", err)
+			}
+		}
+	}
+}
+
+// save writes a snapshot of the state, skipping the write when nothing changed
+// unless force is set. Saves never overlap.
+func (g *Game) save(force bool) error {
+	if g.noSave {
+		return nil
+	}
+	g.saveMu.Lock()
+	defer g.saveMu.Unlock()
+
+	g.mu.Lock()
+	if !g.dirty && !force {
+		g.mu.Unlock()
+		return nil
+	}
+	data := g.snapshotState()
+	g.dirty = false
+	g.mu.Unlock()
+
+	start := time.Now()
+	err := saveData(data, stateFilename)
+	g.metrics.recordSave(time.Since(start), err)
+	if err != nil {
+		g.mu.Lock()
+		g.dirty = true
+		g.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// snapshotState copies everything saveData writes, so it can be encoded
+// without holding g.mu while prices keep updating. Callers must hold g.mu.
+func (g *Game) snapshotState() AppData {
+	coins := make([]*internal.CoinInfo, len(g.coinData))
+	for i, coin := range g.coinData {
+		c := *coin
+		c.PriceHistory = slices.Clone(coin.PriceHistory)
+		coins[i] = &c
+	}
+	alerts := make([]*Alert, len(g.alerts))
+	for i, alert := range g.alerts {
+		a := *alert
+		alerts[i] = &a
+	}
+	window := g.savedWindow
+	if !g.headless {
+		window = currentWindowState()
+		// Compact mode's size is derived from the coin list, keep the full one
+		if g.fullWindow != nil {
+			window.Width, window.Height = g.fullWindow.Width, g.fullWindow.Height
+		}
+	}
+	return AppData{Version: stateVersion, Symbols: g.coinSymbols(), CoinData: coins, Alerts: alerts, Window: window}
+}
@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"main/internal"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestConsumeStreamKeepsQuietDirection(t *testing.T) {
+	btc := &internal.CoinInfo{Symbol: "BTCUSDT", LastPrice: "67000", PreviousPrice: "66000"}
+	eth := &internal.CoinInfo{Symbol: "ETHUSDT", LastPrice: "3500", PreviousPrice: "3400"}
+	g := &Game{coinData: []*internal.CoinInfo{btc, eth}}
+	stream := &internal.PriceStream{Updates: make(chan internal.PriceUpdate, 1)}
+	stream.Updates <- internal.PriceUpdate{Symbol: "BTCUSDT", Price: "66500"}
+
+	g.consumeStream(stream)
+
+	if got := priceDirection(btc); got != -1 {
+		t.Errorf("BTCUSDT direction = %d, want -1 after a lower trade", got)
+	}
+	if got := priceDirection(eth); got != 1 {
+		t.Errorf("ETHUSDT direction = %d, want 1 kept without trades", got)
+	}
+	if len(eth.PriceHistory) != 1 || eth.PriceHistory[0].Price != 3500 {
+		t.Errorf("ETHUSDT history = %v, want one sample at 3500", eth.PriceHistory)
+	}
+}
+
+func TestDedupeSymbols(t *testing.T) {
+	got := dedupeSymbols([]string{"BTCUSDT", "ETHUSDT", "btcusdt", "BTCUSDT", "SOLUSDT", "ETHUSDT"})
+	want := []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}
+	if !slices.Equal(got, want) {
+		t.Errorf("dedupeSymbols = %v, want %v", got, want)
+	}
+}
+
+func TestInitCoinDataCollapsesDuplicates(t *testing.T) {
+	coins := initCoinData(AppData{
+		Symbols: []string{"BTCUSDT", "ETHUSDT", "BTCUSDT"},
+		CoinData: []*internal.CoinInfo{
+			{Symbol: "BTCUSDT", LastPrice: "67000"},
+			{Symbol: "BTCUSDT", LastPrice: "1"},
+			{Symbol: "ETHUSDT"},
+		},
+	})
+
+	var symbols []string
+	for _, coin := range coins {
+		symbols = append(symbols, coin.Symbol)
+	}
+	if want := []string{"BTCUSDT", "ETHUSDT"}; !slices.Equal(symbols, want) {
+		t.Fatalf("coins = %v, want %v", symbols, want)
+	}
+	if coins[0].LastPrice != "67000" {
+		t.Errorf("BTCUSDT LastPrice = %q, want the first saved coin's 67000", coins[0].LastPrice)
+	}
+}
+
+func TestAppendHistoryCapped(t *testing.T) {
+	// At the default 1s interval, where the cap is reached in under 3 hours
+	internal.SetUpdateInterval(time.Second)
+
+	var history []internal.PricePoint
+	for i := range 2 * maxHistoryPoints {
+		history = appendHistory(history, internal.PricePoint{Price: float64(i)})
+	}
+
+	if len(history) != maxHistoryPoints {
+		t.Fatalf("len(history) = %d, want %d", len(history), maxHistoryPoints)
+	}
+	if first, last := history[0].Price, history[len(history)-1].Price; first != maxHistoryPoints || last != 2*maxHistoryPoints-1 {
+		t.Errorf("history spans %g-%g, want the newest %d points", first, last, maxHistoryPoints)
+	}
+}
+
+// TestRSIReference checks rsi against the 14-period worked example in
+// StockCharts' ChartSchool RSI article
+func TestRSIReference(t *testing.T) {
+	closes := []float64{
+		44.3389, 44.0902, 44.1497, 43.6124, 44.3278, 44.8264, 45.0955, 45.4245,
+		45.8433, 46.0826, 45.8931, 46.0328, 45.6140, 46.2820, 46.2820, 46.0028,
+		46.0328, 46.4116, 46.2222, 45.6439, 46.2122, 46.2521, 45.7137, 46.4515,
+		45.7835, 45.3548, 44.0288, 44.1783, 44.2181, 44.5672, 43.4205, 42.6628,
+		43.1314,
+	}
+	// From the 15th close on, rounded to two decimals as published
+	want := []float64{
+		70.53, 66.32, 66.55, 69.41, 66.36, 57.97, 62.93, 63.26, 56.06, 62.38,
+		54.71, 50.42, 39.99, 41.46, 41.87, 45.46, 37.30, 33.08, 37.77,
+	}
+	points := make([]internal.PricePoint, len(closes))
+	for i, price := range closes {
+		points[i].Price = price
+	}
+
+	got := rsi(points, 14)
+	for i := range 14 {
+		if !math.IsNaN(got[i]) {
+			t.Errorf("rsi[%d] = %g, want NaN before a full period", i, got[i])
+		}
+	}
+	for i, w := range want {
+		if math.Abs(got[i+14]-w) > 0.01 {
+			t.Errorf("rsi[%d] = %.4f, want %.2f", i+14, got[i+14], w)
+		}
+	}
+}
+
+// TestOptionRectMatchesHitTest checks each dropdown option is hit-tested over
+// exactly the rect it's drawn in, scrolled or not
+func TestOptionRowHitTest(t *testing.T) {
+	// Rows are 17px tall (85% of the line height) starting 2px below the pill
+	// at y 42, so row r of the view covers y 42+17r to 59+17r
+	g := &Game{physicalLineHeight: 21}
+	dropdown := &Dropdown{IsOpen: true, Bounds: image.Rect(10, 10, 130, 40)}
+	for i := range maxDropdownRows + 8 {
+		dropdown.Options = append(dropdown.Options, fmt.Sprintf("COIN%dUSDT", i))
+	}
+
+	tests := []struct {
+		name   string
+		offset int
+		cursor image.Point
+		want   int
+		wantOK bool
+	}{
+		{"first row top left", 0, image.Pt(10, 42), 0, true},
+		{"first row bottom right", 0, image.Pt(129, 58), 0, true},
+		{"second row top", 0, image.Pt(60, 59), 1, true},
+		{"last visible row after scrolling", 8, image.Pt(60, 235), 19, true},
+		{"first visible row after scrolling", 8, image.Pt(60, 42), 8, true},
+		{"gap above the list", 0, image.Pt(60, 41), 0, false},
+		{"just below the list", 8, image.Pt(60, 246), 0, false},
+		{"just right of the list", 0, image.Pt(130, 50), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dropdown.ScrollOffset = tt.offset
+			got, ok := g.optionRow(dropdown, tt.cursor)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("optionRow(%v) = %d, %v, want %d, %v", tt.cursor, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPriceToYLogClampsNonPositive(t *testing.T) {
+	g := &Game{logScale: true}
+	for _, price := range []float64{0, -5} {
+		if y := g.priceToY(price, 10, 1000); y != 0 {
+			t.Errorf("priceToY(%g) = %g, want 0 at the bottom of the log axis", price, y)
+		}
+	}
+	if y := g.priceToY(100, 10, 1000); math.Abs(y-0.5) > 1e-9 {
+		t.Errorf("priceToY(100) = %g, want 0.5 on a 10-1000 log axis", y)
+	}
+}
@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"image"
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/esset/v2"
+)
+
+// Gap between grid view tiles
+const tileGap = 12.0
+
+// tileRects splits area into n tiles, picking the column count that keeps
+// the tiles closest to the area's aspect ratio
+func tileRects(area chartRect, n int) []chartRect {
+	if n == 0 {
+		return nil
+	}
+	cols := min(max(int(math.Round(math.Sqrt(float64(n)*area.width/area.height))), 1), n)
+	rows := (n + cols - 1) / cols
+	tileW := (area.width - tileGap*float64(cols-1)) / float64(cols)
+	tileH := (area.height - tileGap*float64(rows-1)) / float64(rows)
+
+	tiles := make([]chartRect, n)
+	for i := range tiles {
+		col, row := i%cols, i/cols
+		tiles[i] = chartRect{
+			left:   area.left + float64(col)*(tileW+tileGap),
+			top:    area.top + float64(row)*(tileH+tileGap),
+			width:  tileW,
+			height: tileH,
+		}
+	}
+	return tiles
+}
+
+// drawCoinGrid draws a mini chart of every coin's timeline window in area,
+// each scaled to its own min/max. Callers must hold g.mu.
+func (g *Game) drawCoinGrid(screen *ebiten.Image, area chartRect) {
+	for i, tile := range tileRects(area, len(g.coinData)) {
+		coin := g.coinData[i]
+
+		border := g.theme.Border
+		if i == g.SelectedCoinIndex {
+			border = g.theme.Accent
+		}
+		vector.DrawFilledRect(screen, float32(tile.left), float32(tile.top), float32(tile.width), float32(tile.height), g.theme.Card, false)
+		vector.StrokeRect(screen, float32(tile.left), float32(tile.top), float32(tile.width), float32(tile.height), 1.5, border, false)
+
+		esset.DrawText(screen, g.coinLabel(coin), 0, tile.left+8, tile.top+6, g.fontFace, g.theme.TextSecondary)
+		price := "-"
+		if p, err := strconv.ParseFloat(coin.LastPrice, 64); err == nil {
+			price = formatQuoted(p, coin.Decimals(), g.quoteAsset(coin))
+		}
+		priceColor := g.theme.TextPrimary
+		switch priceDirection(coin) {
+		case 1:
+			priceColor = g.directionColor(true)
+		case -1:
+			priceColor = g.directionColor(false)
+		}
+		priceW, lineH := text.Measure(price, g.fontFace, 0)
+		esset.DrawText(screen, price, 0, tile.left+tile.width-priceW-8, tile.top+6, g.fontFace, priceColor)
+
+		chart := chartRect{tile.left + 8, tile.top + lineH + 16, tile.width - 16, tile.height - lineH - 24}
+		if chart.width <= 0 || chart.height <= 0 {
+			continue
+		}
+		points := g.timelinePoints(coin)
+		if len(points) < 2 {
+			if coin.IsLoading {
+				g.drawSpinner(screen, chart.left+chart.width/2, chart.top+chart.height/2, 8*g.deviceScale)
+			}
+			continue
+		}
+		trendColor := g.directionColor(points[len(points)-1].Price >= points[0].Price)
+		g.drawSparkline(screen, chart, points, trendColor)
+	}
+}
+
+// setGridView switches between the single chart and the grid of all coins,
+// keeping the View dropdown in sync. Callers must hold g.mu.
+func (g *Game) setGridView(on bool) {
+	g.gridView = on
+	for _, dropdown := range g.dropdowns {
+		if dropdown.ID == "view" {
+			dropdown.Selected = 0
+			if on {
+				dropdown.Selected = 1
+			}
+		}
+	}
+}
+
+// selectTile selects the coin whose grid tile is under the cursor and goes
+// back to the single chart. Callers must hold g.mu.
+func (g *Game) selectTile(mx, my int) {
+	cursor := image.Pt(mx, my)
+	for i, tile := range tileRects(g.chartArea, len(g.coinData)) {
+		r := image.Rect(int(tile.left), int(tile.top), int(tile.left+tile.width), int(tile.top+tile.height))
+		if !cursor.In(r) {
+			continue
+		}
+		g.SelectedCoinIndex = i
+		g.resetView()
+		g.setGridView(false)
+		log.Printf("Selected %s from the grid (Index %d)", g.coinData[i].Symbol, i)
+		return
+	}
+}
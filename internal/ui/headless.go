@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"main/internal"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// NewHeadlessGame loads the config and saved state like NewGame but without a
+// window, for use with RunHeadless. It starts the metrics server when
+// opts.MetricsAddr is set.
+func NewHeadlessGame(opts Options) (*Game, error) {
+	g, _ := newGame(opts)
+	g.headless = true
+	g.sortCoins()
+	if opts.MetricsAddr != "" {
+		if err := g.startServer(opts.MetricsAddr); err != nil {
+			return nil, err
+		}
+	}
+
+	go g.fetchDecimals(g.coinSnapshot())
+	go g.runAutosave(time.Duration(g.config.AutosaveIntervalS) * time.Second)
+	return g, nil
+}
+
+// RunHeadless polls prices every UpdateInterval and prints them as a table to
+// w until Shutdown is called
+func (g *Game) RunHeadless(w io.Writer) {
+	ticker := time.NewTicker(internal.UpdateInterval())
+	defer ticker.Stop()
+
+	for {
+		g.updateAllPrices()
+		if g.ctx.Err() != nil {
+			return
+		}
+		g.refreshTickers()
+		g.printPrices(w)
+
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// printPrices writes a symbol/price/24h change table of every coin to w
+func (g *Game) printPrices(w io.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "%s\t\t\t\n", time.Now().Format("15:04:05"))
+	fmt.Fprintln(tw, "SYMBOL\tPRICE\t24H\t")
+	for _, coin := range g.coinData {
+		price := "-"
+		switch {
+		case coin.FetchError != nil:
+			price = "error"
+		case coin.LastPrice != "":
+			if p, err := strconv.ParseFloat(coin.LastPrice, 64); err == nil {
+				price = formatQuoted(p, coin.Decimals(), g.quoteAsset(coin))
+			}
+		}
+		change := "-"
+		if coin.Ticker24h != nil {
+			change = fmt.Sprintf("%+.2f%%", coin.Ticker24h.PriceChangePercent)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t\n", coin.Symbol, price, change)
+	}
+	fmt.Fprintln(tw)
+	tw.Flush()
+}
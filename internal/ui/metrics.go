@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Interval between structured metrics summaries with -verbose, so per-second
+// polling doesn't flood the log
+const metricsLogInterval = time.Minute
+
+// symbolStats counts one symbol's price fetches
+type symbolStats struct {
+	ok      int
+	failed  int
+	latency time.Duration // summed over ok and failed fetches
+}
+
+// metrics accumulates fetch and save counts for the F3 overlay and, with
+// -verbose, logs them as structured records every metricsLogInterval. It has
+// its own lock as it's updated from the fetch goroutines.
+type metrics struct {
+	mu           sync.Mutex
+	logger       *slog.Logger // nil unless verbose
+	symbols      map[string]*symbolStats
+	saves        int
+	saveFailures int
+	lastLog      time.Time
+}
+
+func newMetrics(verbose bool) *metrics {
+	m := &metrics{symbols: make(map[string]*symbolStats), lastLog: time.Now()}
+	if verbose {
+		m.logger = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	}
+	return m
+}
+
+// recordFetch counts a price fetch of symbol that took d
+func (m *metrics) recordFetch(symbol string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.symbols[symbol]
+	if !ok {
+		stats = &symbolStats{}
+		m.symbols[symbol] = stats
+	}
+	if err != nil {
+		stats.failed++
+	} else {
+		stats.ok++
+	}
+	stats.latency += d
+	m.flush()
+}
+
+// recordSave counts a state save that took d
+func (m *metrics) recordSave(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.saves++
+	if err != nil {
+		m.saveFailures++
+	}
+	if m.logger != nil {
+		m.logger.Info("save", "duration_ms", d.Milliseconds(), "ok", err == nil)
+	}
+	m.flush()
+}
+
+// totals sums the fetch counts over all symbols
+func (m *metrics) totals() (ok, failed int, avgLatency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var latency time.Duration
+	for _, stats := range m.symbols {
+		ok += stats.ok
+		failed += stats.failed
+		latency += stats.latency
+	}
+	if ok+failed > 0 {
+		avgLatency = latency / time.Duration(ok+failed)
+	}
+	return ok, failed, avgLatency
+}
+
+// writePrometheus writes the counts in the Prometheus text exposition format
+func (m *metrics) writePrometheus(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	symbols := make([]string, 0, len(m.symbols))
+	for symbol := range m.symbols {
+		symbols = append(symbols, symbol)
+	}
+	slices.Sort(symbols)
+
+	fmt.Fprintln(w, "# HELP ebicrypto_fetches_total Price fetches by symbol and result.")
+	fmt.Fprintln(w, "# TYPE ebicrypto_fetches_total counter")
+	for _, symbol := range symbols {
+		stats := m.symbols[symbol]
+		fmt.Fprintf(w, "ebicrypto_fetches_total{symbol=%q,result=\"ok\"} %d\n", symbol, stats.ok)
+		fmt.Fprintf(w, "ebicrypto_fetches_total{symbol=%q,result=\"failed\"} %d\n", symbol, stats.failed)
+	}
+	fmt.Fprintln(w, "# HELP ebicrypto_fetch_seconds Time spent on price fetches by symbol.")
+	fmt.Fprintln(w, "# TYPE ebicrypto_fetch_seconds summary")
+	for _, symbol := range symbols {
+		stats := m.symbols[symbol]
+		fmt.Fprintf(w, "ebicrypto_fetch_seconds_sum{symbol=%q} %g\n", symbol, stats.latency.Seconds())
+		fmt.Fprintf(w, "ebicrypto_fetch_seconds_count{symbol=%q} %d\n", symbol, stats.ok+stats.failed)
+	}
+	fmt.Fprintln(w, "# HELP ebicrypto_saves_total State saves by result.")
+	fmt.Fprintln(w, "# TYPE ebicrypto_saves_total counter")
+	fmt.Fprintf(w, "ebicrypto_saves_total{result=\"ok\"} %d\n", m.saves-m.saveFailures)
+	fmt.Fprintf(w, "ebicrypto_saves_total{result=\"failed\"} %d\n", m.saveFailures)
+}
+
+// flush logs a record per symbol once metricsLogInterval has passed since
+// the last one. Callers must hold m.mu.
+func (m *metrics) flush() {
+	if m.logger == nil || time.Since(m.lastLog) < metricsLogInterval {
+		return
+	}
+	m.lastLog = time.Now()
+	for symbol, stats := range m.symbols {
+		n := stats.ok + stats.failed
+		if n == 0 {
+			continue
+		}
+		m.logger.Info("fetches", "symbol", symbol, "ok", stats.ok, "failed", stats.failed,
+			"avg_latency_ms", (stats.latency / time.Duration(n)).Milliseconds())
+	}
+	m.logger.Info("saves", "ok", m.saves-m.saveFailures, "failed", m.saveFailures)
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"log"
+	"main/internal"
+)
+
+// Layout version of the state file written by saveData. Bump it and add a
+// case to migrate whenever old files need upgrading to read correctly.
+const stateVersion = 1
+
+// migrate upgrades state loaded from an older file to stateVersion in place.
+// Files from a newer version are left as they are, unknown fields were
+// already dropped by the decoder.
+func migrate(data *AppData) {
+	if data.Version > stateVersion {
+		log.Printf("Warning: state file version %d is newer than %d, some settings may be lost", data.Version, stateVersion)
+		return
+	}
+	for data.Version < stateVersion {
+		switch data.Version {
+		case 0:
+			// Unversioned files predate the symbols list, so the coin order
+			// was only in coin_data, and could hold null entries
+			coins := make([]*internal.CoinInfo, 0, len(data.CoinData))
+			for _, coin := range data.CoinData {
+				if coin != nil && coin.Symbol != "" {
+					coins = append(coins, coin)
+				}
+			}
+			data.CoinData = coins
+			if len(data.Symbols) == 0 {
+				for _, coin := range coins {
+					data.Symbols = append(data.Symbols, coin.Symbol)
+				}
+			}
+			alerts := make([]*Alert, 0, len(data.Alerts))
+			for _, alert := range data.Alerts {
+				if alert != nil {
+					alerts = append(alerts, alert)
+				}
+			}
+			data.Alerts = alerts
+		}
+		data.Version++
+		log.Printf("Migrated state to version %d", data.Version)
+	}
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"sync/atomic"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/esset/v2"
+)
+
+// Number of frames the chart draw time is averaged over
+const perfSamples = 60
+
+// perfStats backs the F3 debug overlay. Everything but lastFetch is only
+// touched from Draw.
+type perfStats struct {
+	drawTimes [perfSamples]time.Duration // ring buffer of chart draw times
+	next      int
+	filled    int
+	points    int          // history points the chart drew last frame
+	lastFetch atomic.Int64 // duration of the last polling round, written by runPolling
+}
+
+// record adds the time one chart draw took
+func (p *perfStats) record(d time.Duration, points int) {
+	p.drawTimes[p.next] = d
+	p.next = (p.next + 1) % perfSamples
+	p.filled = min(p.filled+1, perfSamples)
+	p.points = points
+}
+
+// averageDraw is the mean chart draw time over the recorded frames
+func (p *perfStats) averageDraw() time.Duration {
+	if p.filled == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, d := range p.drawTimes[:p.filled] {
+		sum += d
+	}
+	return sum / time.Duration(p.filled)
+}
+
+// drawPerfOverlay shows frame rate and timing figures in the bottom right
+// corner. It's drawn after everything else and doesn't take g.mu.
+func (g *Game) drawPerfOverlay(screen *ebiten.Image) {
+	fetch := "-"
+	if d := time.Duration(g.perf.lastFetch.Load()); d > 0 {
+		fetch = d.Round(time.Millisecond).String()
+	}
+	ok, failed, avgLatency := g.metrics.totals()
+	lines := []string{
+		fmt.Sprintf("FPS    %.1f", ebiten.ActualFPS()),
+		fmt.Sprintf("TPS    %.1f", ebiten.ActualTPS()),
+		fmt.Sprintf("Chart  %s avg", g.perf.averageDraw().Round(time.Microsecond)),
+		fmt.Sprintf("Points %d", g.perf.points),
+		fmt.Sprintf("Fetch  %s", fetch),
+		fmt.Sprintf("OK     %d / %d failed", ok, failed),
+		fmt.Sprintf("Avg    %s per fetch", avgLatency.Round(time.Millisecond)),
+	}
+
+	boxW, lineH := 0.0, 0.0
+	for _, line := range lines {
+		w, h := text.Measure(line, g.fontFace, 0)
+		boxW, lineH = max(boxW, w), h
+	}
+	boxW += 16
+	boxH := float64(len(lines))*(lineH+4) + 12
+
+	left := float64(screen.Bounds().Dx()) - boxW - 8
+	top := float64(screen.Bounds().Dy()) - boxH - 8
+	vector.DrawFilledRect(screen, float32(left), float32(top), float32(boxW), float32(boxH), color.RGBA{0, 0, 0, 190}, false)
+	for i, line := range lines {
+		esset.DrawText(screen, line, 0, left+8, top+6+float64(i)*(lineH+4), g.fontFace, color.RGBA{120, 255, 120, 255})
+	}
+}
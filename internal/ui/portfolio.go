@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"main/internal"
+	"strconv"
+)
+
+// Quote asset the portfolio total is summed in. Coins quoted in anything else
+// are left out of the total rather than converted.
+const portfolioQuote = baseQuote
+
+// quoteAsset returns the asset coin is priced in, from the exchange info when
+// it's loaded or the symbol suffix otherwise, empty if neither knows it.
+// Callers must hold g.mu.
+func (g *Game) quoteAsset(coin *internal.CoinInfo) string {
+	if info, ok := g.symbolInfo[coin.Symbol]; ok && info.QuoteAsset != "" {
+		return info.QuoteAsset
+	}
+	_, quote, _ := internal.SplitSymbol(coin.Symbol)
+	return quote
+}
+
+// portfolioValue sums Holdings * LastPrice over the coins priced in
+// portfolioQuote, returning each coin's value alongside the total. Coins
+// still loading are skipped; excluded counts held coins in other quotes.
+// Callers must hold g.mu.
+func (g *Game) portfolioValue() (total float64, values map[*internal.CoinInfo]float64, excluded int) {
+	values = make(map[*internal.CoinInfo]float64)
+	for _, coin := range g.coinData {
+		if coin.Holdings <= 0 {
+			continue
+		}
+		if g.quoteAsset(coin) != portfolioQuote {
+			excluded++
+			continue
+		}
+		price, err := strconv.ParseFloat(coin.LastPrice, 64)
+		if err != nil || coin.IsLoading {
+			continue
+		}
+		values[coin] = coin.Holdings * price
+		total += values[coin]
+	}
+	return total, values, excluded
+}
+
+// portfolioSummary is the topbar line for the portfolio, empty while no coin
+// has holdings. Callers must hold g.mu.
+func (g *Game) portfolioSummary() string {
+	total, values, excluded := g.portfolioValue()
+	if len(values) == 0 && excluded == 0 {
+		return ""
+	}
+	summary := fmt.Sprintf("Portfolio: %s %s", formatPrice(total, 2), g.currencyLabel())
+	if excluded > 0 {
+		summary += fmt.Sprintf(" (%d not in %s)", excluded, portfolioQuote)
+	}
+	return summary
+}
+
+// submitHoldingsInput sets the holdings of the selected coin to the typed
+// quantity, 0 removing it from the portfolio
+func (g *Game) submitHoldingsInput() {
+	quantity, err := strconv.ParseFloat(g.holdingsInput.Text, 64)
+	g.holdingsInput.Text = ""
+	g.holdingsInput.Focused = false
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err != nil || quantity < 0 {
+		g.statusText = "Invalid holdings"
+		return
+	}
+	if g.SelectedCoinIndex < 0 || g.SelectedCoinIndex >= len(g.coinData) {
+		return
+	}
+	coin := g.coinData[g.SelectedCoinIndex]
+	coin.Holdings = quantity
+	g.dirty = true
+	g.statusText = fmt.Sprintf("Holdings: %s %s", strconv.FormatFloat(quantity, 'f', -1, 64), coin.Symbol)
+	log.Printf("Set holdings of %s to %f", coin.Symbol, quantity)
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"main/internal"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/esset/v2"
+)
+
+// latestPrice is the selected coin's newest history price, or 0 without
+// history. Callers must hold g.mu.
+func (g *Game) latestPrice() float64 {
+	if g.SelectedCoinIndex < 0 || g.SelectedCoinIndex >= len(g.coinData) {
+		return 0
+	}
+	history := g.coinData[g.SelectedCoinIndex].PriceHistory
+	if len(history) == 0 {
+		return 0
+	}
+	return history[len(history)-1].Price
+}
+
+// changeSince describes how far the latest price moved from price, e.g.
+// "now +12.30 (+0.02%)". Callers must hold g.mu.
+func (g *Game) changeSince(price float64) string {
+	latest := g.latestPrice()
+	if latest == 0 || price == 0 {
+		return ""
+	}
+	delta := latest - price
+	sign := ""
+	if delta >= 0 {
+		sign = "+"
+	}
+	return fmt.Sprintf("now %s%s (%+.2f%%)", sign, g.formatSelectedPrice(delta), delta/price*100)
+}
+
+// pointReadout is the topbar readout of a hovered line chart point. Callers
+// must hold g.mu.
+func (g *Game) pointReadout(point internal.PricePoint) string {
+	return fmt.Sprintf("%s  %s  %s", point.Timestamp.Format("15:04:05"), g.formatSelectedPrice(point.Price), g.changeSince(point.Price))
+}
+
+// candleReadout is the topbar readout of a hovered candle. Callers must hold
+// g.mu.
+func (g *Game) candleReadout(k internal.Kline) string {
+	return fmt.Sprintf("%s  O %s  H %s  L %s  C %s  %s", k.OpenTime.Format("15:04"),
+		g.formatSelectedPrice(k.Open), g.formatSelectedPrice(k.High), g.formatSelectedPrice(k.Low), g.formatSelectedPrice(k.Close),
+		g.changeSince(k.Close))
+}
+
+// hoveredCandle returns the index of the candle of n in area under the
+// cursor, or -1 when the cursor isn't over the chart
+func hoveredCandle(area chartRect, n int) int {
+	mx, my := ebiten.CursorPosition()
+	cx, cy := float64(mx), float64(my)
+	if n == 0 || cx < area.left || cx > area.left+area.width || cy < area.top || cy > area.top+area.height {
+		return -1
+	}
+	return min(int((cx-area.left)/(area.width/float64(n))), n-1)
+}
+
+// drawReadout shows the hovered chart value in the topbar, right-aligned
+// left of the pause button and over the status and price text, so it stays
+// put while the cursor moves. Callers must hold g.mu.
+func (g *Game) drawReadout(screen *ebiten.Image) {
+	if g.readout == "" {
+		return
+	}
+	w, _ := text.Measure(g.readout, g.fontFace, 0)
+	right := float64(g.pauseButton.Min.X - 8)
+	left := math.Min(right-w, float64(screen.Bounds().Dx()-432))
+	vector.DrawFilledRect(screen, float32(left-8), 0, float32(right-left+8), float32(g.topbarHeight), g.theme.Topbar, false)
+	esset.DrawText(screen, g.readout, 0, right-w, float64(g.pauseButton.Min.Y+6), g.fontFace, g.theme.TextPrimary)
+}
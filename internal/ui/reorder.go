@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"image/color"
+	"log"
+	"main/internal"
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/esset/v2"
+)
+
+// How far, in logical pixels, a pressed coin row has to move before it's
+// dragged instead of clicked
+const rowDragThreshold = 5.0
+
+// rowDrag is a coin row held down in the list, waiting to be either clicked
+// or dropped somewhere else
+type rowDrag struct {
+	index  int // row the press started on
+	startY int
+	y      int  // current cursor y
+	moved  bool // past rowDragThreshold, so releasing reorders
+}
+
+// handleRowDrag follows the pressed coin row and, on release, selects it if
+// it was a click or moves it to the row under the cursor if it was dragged
+func (g *Game) handleRowDrag() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	drag := g.rowDrag
+	if drag == nil {
+		return
+	}
+	_, my := ebiten.CursorPosition()
+	drag.y = my
+	if math.Abs(float64(drag.y-drag.startY)) > rowDragThreshold*g.deviceScale {
+		drag.moved = true
+	}
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+
+	g.rowDrag = nil
+	if drag.index >= len(g.coinData) {
+		return
+	}
+	if !drag.moved {
+		g.SelectedCoinIndex = drag.index
+		g.resetView()
+		log.Printf("Clicked on %s (Index %d)", g.coinData[drag.index].Symbol, drag.index)
+		return
+	}
+	g.moveCoin(drag.index, g.rowDropIndex(drag.y))
+}
+
+// rowDropIndex is the list position a row dropped at y lands on. Callers must
+// hold g.mu.
+func (g *Game) rowDropIndex(y int) int {
+	for i := range g.coinData {
+		if y < g.coinRowBounds(i).Max.Y {
+			return i
+		}
+	}
+	return len(g.coinData) - 1
+}
+
+// moveCoin moves the coin at from to index to, keeping the selected and
+// compared coins selected. Pinned coins still stay on top. Callers must hold
+// g.mu.
+func (g *Game) moveCoin(from, to int) {
+	if from == to {
+		return
+	}
+	var selected, compare *internal.CoinInfo
+	if g.SelectedCoinIndex >= 0 && g.SelectedCoinIndex < len(g.coinData) {
+		selected = g.coinData[g.SelectedCoinIndex]
+	}
+	if g.CompareCoinIndex >= 0 && g.CompareCoinIndex < len(g.coinData) {
+		compare = g.coinData[g.CompareCoinIndex]
+	}
+
+	coin := g.coinData[from]
+	g.coinData = slices.Insert(slices.Delete(g.coinData, from, from+1), to, coin)
+	g.SelectedCoinIndex = slices.Index(g.coinData, selected)
+	g.CompareCoinIndex = slices.Index(g.coinData, compare)
+	g.sortCoins()
+	g.dirty = true
+	log.Printf("Moved %s from %d to %d", coin.Symbol, from, to)
+}
+
+// drawRowDrag draws the dragged row as a ghost following the cursor, and a
+// line where it would be dropped. Callers must hold g.mu.
+func (g *Game) drawRowDrag(screen *ebiten.Image, x, width float64, label string) {
+	drag := g.rowDrag
+	if drag == nil || !drag.moved || drag.index >= len(g.coinData) {
+		return
+	}
+
+	target := g.rowDropIndex(drag.y)
+	lineY := float64(g.coinRowBounds(target).Min.Y)
+	if target > drag.index {
+		lineY = float64(g.coinRowBounds(target).Max.Y)
+	}
+	vector.StrokeLine(screen, float32(x), float32(lineY), float32(x+width), float32(lineY), 2*float32(g.deviceScale), g.theme.Accent, false)
+
+	ghostY := float64(drag.y) - g.physicalLineHeight/2
+	bg, fg := g.theme.ControlActive, g.theme.TextPrimary
+	vector.DrawFilledRect(screen, float32(x-4), float32(ghostY), float32(width+8), float32(g.physicalLineHeight), color.NRGBA{bg.R, bg.G, bg.B, 180}, false)
+	esset.DrawText(screen, label, 0, x, ghostY+2*g.deviceScale, g.fontFace, color.NRGBA{fg.R, fg.G, fg.B, 200})
+}
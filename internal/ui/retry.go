@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"image"
+	"main/internal"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/esset/v2"
+)
+
+// retryButton is the refresh button at the right end of coin row i, shown
+// while the coin's last fetch failed
+func (g *Game) retryButton(i int) image.Rectangle {
+	row := g.coinRowBounds(i)
+	size := row.Dy() * 4 / 5
+	margin := (row.Dy() - size) / 2
+	return image.Rect(row.Max.X-size-margin, row.Min.Y+margin, row.Max.X-margin, row.Min.Y+margin+size)
+}
+
+// retryable reports whether coin shows the retry button. Callers must hold
+// g.mu.
+func retryable(coin *internal.CoinInfo) bool {
+	return coin.FetchError != nil && !coin.Delisted && !coin.IsLoading
+}
+
+// retryCoin fetches coin's price right away instead of on the next round.
+// It's outside the polling rounds' wait group, so it can start at any time.
+// Callers must hold g.mu.
+func (g *Game) retryCoin(coin *internal.CoinInfo) {
+	if !retryable(coin) {
+		return
+	}
+	coin.IsLoading = true
+	g.statusText = "Retrying " + coin.Symbol
+	go g.fetchCoin(coin)
+}
+
+// drawRetryButton draws a circular arrow in r
+func (g *Game) drawRetryButton(screen *ebiten.Image, r image.Rectangle) {
+	hovered := image.Pt(ebiten.CursorPosition()).In(r)
+	bg := g.theme.Control
+	if hovered {
+		bg = g.theme.ControlActive
+	}
+	vector.DrawFilledRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), bg, false)
+
+	cx, cy := float32(r.Min.X)+float32(r.Dx())/2, float32(r.Min.Y)+float32(r.Dy())/2
+	radius := float32(min(r.Dx(), r.Dy())) * 0.3
+	width := max(radius/3, 1)
+	const start, end = -math.Pi / 3, 3 * math.Pi / 2
+	arc := &vector.Path{}
+	arc.Arc(cx, cy, radius, start, end, vector.Clockwise)
+	g.drawPath(screen, arc, width, g.theme.TextPrimary)
+
+	// Arrow head at the start of the arc, pointing along it
+	tipX, tipY := cx+radius*float32(math.Cos(start)), cy+radius*float32(math.Sin(start))
+	head := &vector.Path{}
+	head.MoveTo(tipX-radius*0.8, tipY-radius*0.1)
+	head.LineTo(tipX, tipY)
+	head.LineTo(tipX+radius*0.1, tipY-radius*0.8)
+	g.drawPath(screen, head, width, g.theme.TextPrimary)
+}
+
+// drawFetchErrorTip shows the fetch error of the coin under the cursor in a
+// box below its row, over the chart. Callers must hold g.mu.
+func (g *Game) drawFetchErrorTip(screen *ebiten.Image) {
+	if g.contextMenu != nil || g.rowDrag != nil {
+		return
+	}
+	i := g.coinRowAt(ebiten.CursorPosition())
+	if i < 0 {
+		return
+	}
+	coin := g.coinData[i]
+	if coin.FetchError == nil || coin.Delisted {
+		return
+	}
+	message := coin.FetchError.Error()
+	w, h := text.Measure(message, g.fontFace, 0)
+	boxW, boxH := w+16, h+12
+	row := g.coinRowBounds(i)
+	boxX := math.Max(math.Min(float64(row.Min.X)+10*g.deviceScale, float64(screen.Bounds().Dx())-boxW), 0)
+	boxY := float64(row.Max.Y)
+	if boxY+boxH > float64(screen.Bounds().Dy()) {
+		boxY = float64(row.Min.Y) - boxH
+	}
+
+	vector.DrawFilledRect(screen, float32(boxX), float32(boxY), float32(boxW), float32(boxH), g.theme.Topbar, false)
+	vector.StrokeRect(screen, float32(boxX), float32(boxY), float32(boxW), float32(boxH), 1, g.theme.Border, false)
+	esset.DrawText(screen, message, 0, boxX+8, boxY+6, g.fontFace, g.theme.Down)
+}
@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"main/internal"
+)
+
+// errNothingSalvaged is returned by salvageState when no part of the state
+// could be read
+var errNothingSalvaged = errors.New("no state could be recovered")
+
+// decodeState decodes a whole state file and migrates it to stateVersion
+func decodeState(raw []byte) (AppData, error) {
+	var data AppData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return AppData{}, fmt.Errorf("failed to decode state data: %w", err)
+	}
+	migrate(&data)
+	return data, nil
+}
+
+// salvageState reads what it can of a state file decodeState rejected. It
+// walks the top level object a field at a time and coin_data and alerts an
+// entry at a time, so a malformed coin is skipped and logged instead of
+// discarding the rest, and a truncated file keeps everything before the cut.
+// Coins without a symbol are dropped.
+func salvageState(raw []byte) (AppData, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('{') {
+		return AppData{}, errNothingSalvaged
+	}
+
+	var data AppData
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		key, _ := tok.(string)
+
+		var ok bool
+		switch key {
+		case "version":
+			ok = salvageField(decoder, key, &data.Version)
+		case "symbols":
+			ok = salvageField(decoder, key, &data.Symbols)
+		case "window":
+			ok = salvageField(decoder, key, &data.Window)
+		case "coin_data":
+			ok = salvageList(decoder, key, func(entry json.RawMessage) error {
+				var coin *internal.CoinInfo
+				if err := json.Unmarshal(entry, &coin); err != nil {
+					return err
+				}
+				if coin == nil || coin.Symbol == "" {
+					return errors.New("no symbol")
+				}
+				data.CoinData = append(data.CoinData, coin)
+				return nil
+			})
+		case "alerts":
+			ok = salvageList(decoder, key, func(entry json.RawMessage) error {
+				var alert *Alert
+				if err := json.Unmarshal(entry, &alert); err != nil {
+					return err
+				}
+				if alert != nil {
+					data.Alerts = append(data.Alerts, alert)
+				}
+				return nil
+			})
+		default:
+			var skipped json.RawMessage
+			ok = decoder.Decode(&skipped) == nil
+		}
+		if !ok {
+			// The input is malformed past here
+			break
+		}
+	}
+
+	if len(data.Symbols) == 0 && len(data.CoinData) == 0 {
+		return AppData{}, errNothingSalvaged
+	}
+	migrate(&data)
+	return data, nil
+}
+
+// salvageField decodes the value of key into dest. A value of the wrong type
+// is logged and left out; ok is false once the input itself is malformed.
+func salvageField(decoder *json.Decoder, key string, dest any) (ok bool) {
+	var value json.RawMessage
+	if err := decoder.Decode(&value); err != nil {
+		log.Printf("State file cut off at %s: %v", key, err)
+		return false
+	}
+	if err := json.Unmarshal(value, dest); err != nil {
+		log.Printf("Skipping unreadable %s in state file: %v", key, err)
+	}
+	return true
+}
+
+// salvageList hands each entry of the array value of key to add, logging and
+// skipping the ones it rejects. A value that isn't an array is skipped
+// whole. ok is false once the input itself is malformed, with the entries
+// before that point kept.
+func salvageList(decoder *json.Decoder, key string, add func(json.RawMessage) error) (ok bool) {
+	tok, err := decoder.Token()
+	if err != nil {
+		log.Printf("State file cut off at %s: %v", key, err)
+		return false
+	}
+	switch tok {
+	case json.Delim('['):
+	case nil:
+		return true
+	case json.Delim('{'):
+		log.Printf("Skipping unreadable %s in state file", key)
+		return skipObject(decoder)
+	default:
+		// Any other scalar was consumed whole by Token
+		log.Printf("Skipping unreadable %s in state file", key)
+		return true
+	}
+
+	var value json.RawMessage
+	for i := 0; decoder.More(); i++ {
+		if err := decoder.Decode(&value); err != nil {
+			log.Printf("State file cut off at %s[%d]: %v", key, i, err)
+			return false
+		}
+		if err := add(value); err != nil {
+			log.Printf("Skipping unreadable %s[%d] in state file: %v", key, i, err)
+		}
+	}
+	_, err = decoder.Token() // closing ]
+	return err == nil
+}
+
+// skipObject consumes the rest of an object whose opening brace was read
+func skipObject(decoder *json.Decoder) bool {
+	for depth := 1; depth > 0; {
+		tok, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return true
+}
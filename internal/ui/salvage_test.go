@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"main/internal"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTruncatedState(t *testing.T) {
+	raw, err := json.Marshal(AppData{
+		Version:  stateVersion,
+		Symbols:  []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"},
+		CoinData: []*internal.CoinInfo{{Symbol: "BTCUSDT", LastPrice: "67000"}, {Symbol: "ETHUSDT"}, {Symbol: "SOLUSDT"}},
+		Alerts:   []*Alert{{Symbol: "BTCUSDT", Direction: "above", Target: 70000}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every cut short of the whole file is an error for the strict decode,
+	// and salvaging it never panics
+	for n := range len(raw) {
+		if _, err := decodeState(raw[:n]); err == nil {
+			t.Errorf("decodeState accepted the first %d of %d bytes", n, len(raw))
+		}
+		salvageState(raw[:n])
+	}
+
+	cut := strings.Index(string(raw), `{"symbol":"SOLUSDT"`) + 5
+	data, err := salvageState(raw[:cut])
+	if err != nil {
+		t.Fatalf("salvageState: %v", err)
+	}
+	var symbols []string
+	for _, coin := range data.CoinData {
+		symbols = append(symbols, coin.Symbol)
+	}
+	if want := []string{"BTCUSDT", "ETHUSDT"}; !slices.Equal(symbols, want) {
+		t.Errorf("salvaged coins %v, want %v from before the cut", symbols, want)
+	}
+}
+
+func TestSalvageGarbage(t *testing.T) {
+	for _, raw := range []string{"", "not json", "[1, 2", `"state"`} {
+		if _, err := salvageState([]byte(raw)); !errors.Is(err, errNothingSalvaged) {
+			t.Errorf("salvageState(%q) error = %v, want errNothingSalvaged", raw, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"main/internal"
+	"net"
+	"net/http"
+	"time"
+)
+
+// The server is unhealthy once no price has arrived for this many update
+// intervals
+const healthyIntervals = 3
+
+// How long shutdown waits for in-flight requests
+const serverShutdownTimeout = 2 * time.Second
+
+// coinPrice is a coin as served by /prices
+type coinPrice struct {
+	Symbol    string   `json:"symbol"`
+	Price     string   `json:"price,omitempty"`
+	Change24h *float64 `json:"change_24h,omitempty"` // percent, when the 24h ticker is known
+	Delisted  bool     `json:"delisted,omitempty"`
+	Error     string   `json:"error,omitempty"` // last fetch error
+}
+
+// startServer serves /healthz, /prices and /metrics on addr until the game
+// shuts down. The listener is opened before returning, so a bad or taken
+// address fails here.
+func (g *Game) startServer(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", g.serveHealth)
+	mux.HandleFunc("GET /prices", g.servePrices)
+	mux.HandleFunc("GET /metrics", g.serveMetrics)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	g.serverDone = make(chan struct{})
+	go func() {
+		defer close(g.serverDone)
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Metrics server stopped: %v", err)
+		}
+	}()
+	go func() {
+		<-g.ctx.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Could not shut down metrics server: %v", err)
+		}
+	}()
+
+	log.Printf("Serving metrics on %s", listener.Addr())
+	return nil
+}
+
+// serveHealth answers 200 while prices are arriving and 503 once the last
+// one is more than healthyIntervals update intervals old
+func (g *Game) serveHealth(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	last := g.lastSuccessfulUpdate
+	g.mu.Unlock()
+
+	if last.IsZero() || time.Since(last) > healthyIntervals*internal.UpdateInterval() {
+		http.Error(w, "no recent prices", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintf(w, "ok, last price %s ago\n", time.Since(last).Round(time.Millisecond))
+}
+
+// servePrices writes every coin's current price as JSON, in display order
+func (g *Game) servePrices(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	prices := make([]coinPrice, len(g.coinData))
+	for i, coin := range g.coinData {
+		prices[i] = coinPrice{Symbol: coin.Symbol, Price: coin.LastPrice, Delisted: coin.Delisted}
+		if coin.Ticker24h != nil {
+			change := coin.Ticker24h.PriceChangePercent
+			prices[i].Change24h = &change
+		}
+		if coin.FetchError != nil {
+			prices[i].Error = coin.FetchError.Error()
+		}
+	}
+	g.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(prices); err != nil {
+		log.Printf("Could not write prices: %v", err)
+	}
+}
+
+// serveMetrics writes the fetch and save counts in the Prometheus text format
+func (g *Game) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	g.metrics.writePrometheus(w)
+}
@@ -0,0 +1,377 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"main/internal"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/esset/v2"
+)
+
+const (
+	settingsWidth     = 380
+	settingsRowHeight = 40
+)
+
+// SettingsPanel is the modal opened by the topbar gear. The steppers and theme
+// apply right away, the text fields on Enter, and everything is written to
+// the config file on close.
+type SettingsPanel struct {
+	IsOpen     bool
+	Bounds     image.Rectangle // laid out each frame, centered on the screen
+	interval   *TextInput
+	background *TextInput // update interval of coins other than the selected one
+	apiURL     *TextInput
+	message    string // last validation error, shown above the buttons
+
+	precisionDown image.Rectangle
+	precisionUp   image.Rectangle
+	gridDown      image.Rectangle
+	gridUp        image.Rectangle
+	themeButton   image.Rectangle
+	schemeButton  image.Rectangle
+	soundButton   image.Rectangle
+	volumeDown    image.Rectangle
+	volumeUp      image.Rectangle
+	widthDown     image.Rectangle
+	widthUp       image.Rectangle
+	markersButton image.Rectangle
+	axisButton    image.Rectangle
+	closeButton   image.Rectangle
+}
+
+func newSettingsPanel() *SettingsPanel {
+	return &SettingsPanel{
+		interval:   &TextInput{MaxLen: 7, Filter: digitFilter},
+		background: &TextInput{MaxLen: 7, Filter: digitFilter},
+		apiURL:     &TextInput{MaxLen: 64, Filter: urlFilter},
+	}
+}
+
+// digitFilter accepts digits only
+func digitFilter(r rune) rune {
+	if r >= '0' && r <= '9' {
+		return r
+	}
+	return 0
+}
+
+// urlFilter accepts printable ASCII without spaces
+func urlFilter(r rune) rune {
+	if r > ' ' && r <= '~' {
+		return r
+	}
+	return 0
+}
+
+// open fills the fields from the current config
+func (p *SettingsPanel) open(g *Game) {
+	p.IsOpen = true
+	p.message = ""
+	p.interval.Text = strconv.Itoa(g.config.UpdateIntervalMs)
+	p.background.Text = strconv.Itoa(g.config.BackgroundIntervalMs)
+	p.apiURL.Text = g.config.APIURL
+	p.interval.Focused = false
+	p.background.Focused = false
+	p.apiURL.Focused = false
+}
+
+// close applies any pending text field edits and saves the config
+func (p *SettingsPanel) close(g *Game) {
+	p.applyInterval(g)
+	p.applyBackground(g)
+	p.applyAPIURL(g)
+	p.IsOpen = false
+	p.interval.Focused = false
+	p.background.Focused = false
+	p.apiURL.Focused = false
+
+	if err := saveConfig(g.config, configFilename); err != nil {
+		log.Printf("Error saving config: %v", err)
+	}
+}
+
+// applyInterval sets the update interval from its field, or restores the
+// field if the value is out of range
+func (p *SettingsPanel) applyInterval(g *Game) {
+	ms, err := strconv.Atoi(p.interval.Text)
+	if err != nil || ms < 100 {
+		p.message = "Interval must be at least 100 ms"
+		p.interval.Text = strconv.Itoa(g.config.UpdateIntervalMs)
+		return
+	}
+	g.config.UpdateIntervalMs = ms
+	internal.SetUpdateInterval(time.Duration(ms) * time.Millisecond)
+}
+
+// applyBackground sets the update interval of unselected coins from its
+// field, or restores the field if the value is out of range
+func (p *SettingsPanel) applyBackground(g *Game) {
+	ms, err := strconv.Atoi(p.background.Text)
+	if err != nil || ms < 100 {
+		p.message = "Interval must be at least 100 ms"
+		p.background.Text = strconv.Itoa(g.config.BackgroundIntervalMs)
+		return
+	}
+	g.config.BackgroundIntervalMs = ms
+}
+
+// applyAPIURL points requests at the URL in its field with a fresh HTTP
+// client, or restores the field if the URL is invalid
+func (p *SettingsPanel) applyAPIURL(g *Game) {
+	if p.apiURL.Text == g.config.APIURL {
+		return
+	}
+	if !validAPIURL(p.apiURL.Text) {
+		p.message = "API URL must be http(s)://host"
+		p.apiURL.Text = g.config.APIURL
+		return
+	}
+	g.config.APIURL = p.apiURL.Text
+	internal.SetAPIURL(g.config.APIURL)
+	internal.SetHTTPTimeout(time.Duration(g.config.HTTPTimeoutMs) * time.Millisecond)
+	log.Printf("API URL changed to %s", g.config.APIURL)
+}
+
+// setPrecision changes the fallback price precision by delta, within 0-8
+func (p *SettingsPanel) setPrecision(g *Game, delta int) {
+	precision := min(max(g.config.PricePrecision+delta, 0), 8)
+	g.config.PricePrecision = precision
+	internal.SetPricePrecision(precision)
+}
+
+// setGridLines changes the chart grid density by delta, within the config range
+func (p *SettingsPanel) setGridLines(g *Game, delta int) {
+	g.config.GridLines = min(max(g.config.GridLines+delta, minGridLines), maxGridLines)
+}
+
+// setVolume changes the alert sound volume by delta, within 0-100
+func (p *SettingsPanel) setVolume(g *Game, delta int) {
+	g.config.AlertVolume = min(max(g.config.AlertVolume+delta, 0), 100)
+}
+
+// setLineWidth changes the price line width by delta, within the config range
+func (p *SettingsPanel) setLineWidth(g *Game, delta float64) {
+	g.config.LineWidth = min(max(g.config.LineWidth+delta, minLineWidth), maxLineWidth)
+}
+
+// nextAxisSide cycles the price axis button through the sides
+var nextAxisSide = map[string]string{axisLeft: axisRight, axisRight: axisBoth, axisBoth: axisLeft}
+
+// axisSideNames labels the price axis button
+var axisSideNames = map[string]string{axisLeft: "Left", axisRight: "Right", axisBoth: "Both"}
+
+// layout positions the panel and its controls for a screen of the given size
+func (p *SettingsPanel) layout(screenWidth, screenHeight int) {
+	height := 14*settingsRowHeight + 24
+	x := (screenWidth - settingsWidth) / 2
+	y := max((screenHeight-height)/2, 0)
+	p.Bounds = image.Rect(x, y, x+settingsWidth, y+height)
+
+	controlX := x + 190
+	rowY := func(row int) int { return y + 48 + row*settingsRowHeight }
+	rowRect := func(left, row, width int) image.Rectangle {
+		return image.Rect(left, rowY(row), left+width, rowY(row)+30)
+	}
+
+	p.interval.Bounds = rowRect(controlX, 0, 100)
+	p.background.Bounds = rowRect(controlX, 1, 100)
+	p.precisionDown = rowRect(controlX, 2, 30)
+	p.precisionUp = rowRect(controlX+70, 2, 30)
+	p.gridDown = rowRect(controlX, 3, 30)
+	p.gridUp = rowRect(controlX+70, 3, 30)
+	p.themeButton = rowRect(controlX, 4, 100)
+	p.schemeButton = rowRect(controlX, 5, 100)
+	p.soundButton = rowRect(controlX, 6, 100)
+	p.volumeDown = rowRect(controlX, 7, 30)
+	p.volumeUp = rowRect(controlX+70, 7, 30)
+	p.widthDown = rowRect(controlX, 8, 30)
+	p.widthUp = rowRect(controlX+70, 8, 30)
+	p.markersButton = rowRect(controlX, 9, 100)
+	p.axisButton = rowRect(controlX, 10, 100)
+	p.apiURL.Bounds = rowRect(x+16, 12, settingsWidth-32)
+	p.closeButton = image.Rect(x+settingsWidth-76, p.Bounds.Max.Y-42, x+settingsWidth-16, p.Bounds.Max.Y-12)
+}
+
+// Update handles all input while the panel is open, so none of it reaches
+// the widgets behind it
+func (p *SettingsPanel) Update(g *Game) {
+	typing := p.interval.Focused || p.background.Focused || p.apiURL.Focused
+	if !typing && inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		p.close(g)
+		return
+	}
+
+	if p.interval.Update() {
+		p.message = ""
+		p.applyInterval(g)
+		p.interval.Focused = false
+	}
+	if p.background.Update() {
+		p.message = ""
+		p.applyBackground(g)
+		p.background.Focused = false
+	}
+	if p.apiURL.Update() {
+		p.message = ""
+		p.applyAPIURL(g)
+		p.apiURL.Focused = false
+	}
+
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+	mx, my := ebiten.CursorPosition()
+	cursor := image.Pt(mx, my)
+
+	// Clicking outside the panel closes it
+	if !cursor.In(p.Bounds) || cursor.In(p.closeButton) {
+		p.close(g)
+		return
+	}
+
+	p.interval.Focused = cursor.In(p.interval.Bounds)
+	p.background.Focused = cursor.In(p.background.Bounds)
+	p.apiURL.Focused = cursor.In(p.apiURL.Bounds)
+	switch {
+	case cursor.In(p.precisionDown):
+		p.setPrecision(g, -1)
+	case cursor.In(p.precisionUp):
+		p.setPrecision(g, 1)
+	case cursor.In(p.gridDown):
+		p.setGridLines(g, -1)
+	case cursor.In(p.gridUp):
+		p.setGridLines(g, 1)
+	case cursor.In(p.themeButton):
+		g.toggleTheme()
+	case cursor.In(p.schemeButton):
+		if g.config.ColorScheme == schemeColorblind {
+			g.config.ColorScheme = schemeClassic
+		} else {
+			g.config.ColorScheme = schemeColorblind
+		}
+	case cursor.In(p.soundButton):
+		g.config.AlertSound = !g.config.AlertSound
+	case cursor.In(p.volumeDown):
+		p.setVolume(g, -10)
+	case cursor.In(p.volumeUp):
+		p.setVolume(g, 10)
+	case cursor.In(p.widthDown):
+		p.setLineWidth(g, -lineWidthStep)
+	case cursor.In(p.widthUp):
+		p.setLineWidth(g, lineWidthStep)
+	case cursor.In(p.markersButton):
+		g.config.PointMarkers = !g.config.PointMarkers
+	case cursor.In(p.axisButton):
+		g.config.AxisSide = nextAxisSide[g.config.AxisSide]
+	}
+}
+
+// Draw dims the screen and draws the panel over it
+func (p *SettingsPanel) Draw(screen *ebiten.Image, g *Game) {
+	p.layout(screen.Bounds().Dx(), screen.Bounds().Dy())
+
+	vector.DrawFilledRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()), color.RGBA{0, 0, 0, 120}, false)
+	b := p.Bounds
+	vector.DrawFilledRect(screen, float32(b.Min.X), float32(b.Min.Y), float32(b.Dx()), float32(b.Dy()), g.theme.Card, false)
+	vector.StrokeRect(screen, float32(b.Min.X), float32(b.Min.Y), float32(b.Dx()), float32(b.Dy()), 1.5, g.theme.Border, false)
+	esset.DrawText(screen, "Settings", 0, float64(b.Min.X+16), float64(b.Min.Y+14), g.fontFace, g.theme.TextPrimary)
+
+	label := func(s string, control image.Rectangle) {
+		esset.DrawText(screen, s, 0, float64(b.Min.X+16), float64(control.Min.Y+6), g.fontFace, g.theme.TextSecondary)
+	}
+
+	label("Update interval (ms)", p.interval.Bounds)
+	g.drawTextInput(screen, p.interval)
+
+	label("Background (ms)", p.background.Bounds)
+	g.drawTextInput(screen, p.background)
+
+	label("Price precision", p.precisionDown)
+	g.drawButton(screen, p.precisionDown, "-")
+	esset.DrawText(screen, strconv.Itoa(g.config.PricePrecision), 0, float64(p.precisionDown.Max.X+15), float64(p.precisionDown.Min.Y+6), g.fontFace, g.theme.TextPrimary)
+	g.drawButton(screen, p.precisionUp, "+")
+
+	label("Grid lines", p.gridDown)
+	g.drawButton(screen, p.gridDown, "-")
+	esset.DrawText(screen, strconv.Itoa(g.config.GridLines), 0, float64(p.gridDown.Max.X+15), float64(p.gridDown.Min.Y+6), g.fontFace, g.theme.TextPrimary)
+	g.drawButton(screen, p.gridUp, "+")
+
+	label("Theme", p.themeButton)
+	themeName := "Dark"
+	if g.theme.Name == lightTheme.Name {
+		themeName = "Light"
+	}
+	g.drawButton(screen, p.themeButton, themeName)
+
+	label("Up/down colors", p.schemeButton)
+	schemeName := "Classic"
+	if g.config.ColorScheme == schemeColorblind {
+		schemeName = "Colorblind"
+	}
+	g.drawButton(screen, p.schemeButton, schemeName)
+
+	label("Alert sound", p.soundButton)
+	soundName := "Off"
+	if g.config.AlertSound {
+		soundName = "On"
+	}
+	g.drawButton(screen, p.soundButton, soundName)
+
+	label("Alert volume", p.volumeDown)
+	g.drawButton(screen, p.volumeDown, "-")
+	esset.DrawText(screen, strconv.Itoa(g.config.AlertVolume), 0, float64(p.volumeDown.Max.X+8), float64(p.volumeDown.Min.Y+6), g.fontFace, g.theme.TextPrimary)
+	g.drawButton(screen, p.volumeUp, "+")
+
+	label("Line width", p.widthDown)
+	g.drawButton(screen, p.widthDown, "-")
+	esset.DrawText(screen, strconv.FormatFloat(g.config.LineWidth, 'f', 1, 64), 0, float64(p.widthDown.Max.X+8), float64(p.widthDown.Min.Y+6), g.fontFace, g.theme.TextPrimary)
+	g.drawButton(screen, p.widthUp, "+")
+
+	label("Point markers", p.markersButton)
+	markersName := "Off"
+	if g.config.PointMarkers {
+		markersName = "On"
+	}
+	g.drawButton(screen, p.markersButton, markersName)
+
+	label("Price axis", p.axisButton)
+	g.drawButton(screen, p.axisButton, axisSideNames[g.config.AxisSide])
+
+	esset.DrawText(screen, "API base URL", 0, float64(b.Min.X+16), float64(p.apiURL.Bounds.Min.Y-settingsRowHeight+6), g.fontFace, g.theme.TextSecondary)
+	g.drawTextInput(screen, p.apiURL)
+
+	if p.message != "" {
+		esset.DrawText(screen, p.message, 0, float64(b.Min.X+16), float64(p.closeButton.Min.Y+6), g.fontFace, g.theme.Down)
+	}
+	g.drawButton(screen, p.closeButton, "Close")
+}
+
+// drawButton draws a plain topbar-style button with label
+func (g *Game) drawButton(screen *ebiten.Image, r image.Rectangle, label string) {
+	vector.DrawFilledRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), g.theme.Control, false)
+	vector.StrokeRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), 1.5, g.theme.Border, false)
+	esset.DrawText(screen, label, 0, float64(r.Min.X+8), float64(r.Min.Y+6), g.fontFace, g.theme.TextPrimary)
+}
+
+// drawGear draws a cog icon centered in r
+func drawGear(screen *ebiten.Image, r image.Rectangle, clr, hole color.Color) {
+	cx, cy := float32(r.Min.X+r.Dx()/2), float32(r.Min.Y+r.Dy()/2)
+	radius := float32(min(r.Dx(), r.Dy())) * 0.3
+
+	const teeth = 8
+	for i := range teeth {
+		angle := 2 * math.Pi * float64(i) / teeth
+		dx, dy := float32(math.Cos(angle)), float32(math.Sin(angle))
+		vector.StrokeLine(screen, cx, cy, cx+dx*radius*1.35, cy+dy*radius*1.35, radius*0.5, clr, true)
+	}
+	vector.DrawFilledCircle(screen, cx, cy, radius, clr, true)
+	vector.DrawFilledCircle(screen, cx, cy, radius*0.45, hole, true)
+}
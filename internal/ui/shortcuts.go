@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"image/color"
+	"main/internal"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/temidaradev/esset/v2"
+)
+
+// Shortcut is a keyboard binding. Update dispatches keys from shortcuts and
+// the help overlay lists them, so the two can't disagree.
+type Shortcut struct {
+	Key         ebiten.Key
+	Ctrl        bool   // needs Ctrl, or Cmd on macOS
+	Global      bool   // also works over the settings panel, menus and text inputs
+	Label       string // key as shown in the help overlay
+	Description string
+	Handler     func(g *Game)
+}
+
+var shortcuts = []Shortcut{
+	{Key: ebiten.KeyF1, Label: "F1", Description: "Show this help", Handler: func(g *Game) { g.showHelp = true }},
+	{Key: ebiten.KeyF3, Global: true, Label: "F3", Description: "Toggle the performance overlay", Handler: func(g *Game) { g.showPerf = !g.showPerf }},
+	{Key: ebiten.KeyF12, Global: true, Label: "F12", Description: "Save the chart as a PNG", Handler: func(g *Game) { g.capturePending = true }},
+	{Key: ebiten.KeyC, Ctrl: true, Label: "Ctrl+C", Description: "Copy the selected coin's price", Handler: (*Game).copySelectedPrice},
+	{Key: ebiten.KeySpace, Label: "Space", Description: "Pause or resume price updates", Handler: (*Game).togglePause},
+	{Key: ebiten.KeyH, Label: "H", Description: "Show the session high and low", Handler: func(g *Game) {
+		g.mu.Lock()
+		g.showExtremes = !g.showExtremes
+		g.mu.Unlock()
+	}},
+	{Key: ebiten.KeyL, Label: "L", Description: "Lock the price axis range, L again to fit the data", Handler: (*Game).toggleRangeLock},
+	{Key: ebiten.KeyV, Label: "V", Description: "Shade candles by their volume", Handler: (*Game).toggleVolumeShading},
+	{Key: ebiten.KeyS, Label: "S", Description: "Show the bid/ask spread", Handler: (*Game).toggleSpread},
+	{Key: ebiten.KeyM, Label: "M", Description: "Compact mode, the coin list without the chart", Handler: (*Game).toggleCompact},
+	{Key: ebiten.KeyT, Label: "T", Description: "Keep the window on top of other apps", Handler: (*Game).toggleAlwaysOnTop},
+	{Key: ebiten.KeyB, Label: "B", Description: "Big number mode, B again to leave", Handler: func(g *Game) { g.bigNumberMode = true }},
+	{Key: ebiten.KeyR, Label: "R", Description: "Reset the chart zoom", Handler: func(g *Game) {
+		g.mu.Lock()
+		g.resetView()
+		g.mu.Unlock()
+	}},
+}
+
+// handleShortcuts runs the first shortcut whose key was just pressed,
+// limited to the global ones when global is set, and reports whether one ran
+func (g *Game) handleShortcuts(global bool) bool {
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)
+	for _, shortcut := range shortcuts {
+		if shortcut.Global != global || shortcut.Ctrl != ctrl || !inpututil.IsKeyJustPressed(shortcut.Key) {
+			continue
+		}
+		shortcut.Handler(g)
+		return true
+	}
+	return false
+}
+
+// copySelectedPrice copies the selected coin's price to the clipboard
+func (g *Game) copySelectedPrice() {
+	g.mu.Lock()
+	var selected *internal.CoinInfo
+	if g.SelectedCoinIndex >= 0 && g.SelectedCoinIndex < len(g.coinData) {
+		selected = g.coinData[g.SelectedCoinIndex]
+	}
+	g.mu.Unlock()
+	if selected != nil {
+		g.copyPrice(selected)
+	}
+}
+
+// drawHelp dims the app and lists the shortcuts in a centered panel
+func (g *Game) drawHelp(screen *ebiten.Image) {
+	size := screen.Bounds().Size()
+	vector.DrawFilledRect(screen, 0, 0, float32(size.X), float32(size.Y), color.RGBA{0, 0, 0, 150}, false)
+
+	keyW, descW, lineH := 0.0, 0.0, 0.0
+	for _, shortcut := range shortcuts {
+		w, h := text.Measure(shortcut.Label, g.fontFace, 0)
+		keyW, lineH = max(keyW, w), h
+		w, _ = text.Measure(shortcut.Description, g.fontFace, 0)
+		descW = max(descW, w)
+	}
+	rowH := lineH + 8
+	width := keyW + descW + 56
+	height := float64(len(shortcuts)+3)*rowH + 16
+	left := (float64(size.X) - width) / 2
+	top := (float64(size.Y) - height) / 2
+
+	vector.DrawFilledRect(screen, float32(left), float32(top), float32(width), float32(height), g.theme.Card, false)
+	vector.StrokeRect(screen, float32(left), float32(top), float32(width), float32(height), 1.5, g.theme.Border, false)
+	esset.DrawText(screen, "Keyboard shortcuts", 0, left+16, top+12, g.fontFace, g.theme.TextPrimary)
+	for i, shortcut := range shortcuts {
+		y := top + 12 + float64(i+1)*rowH + 4
+		esset.DrawText(screen, shortcut.Label, 0, left+16, y, g.fontFace, g.theme.Accent)
+		esset.DrawText(screen, shortcut.Description, 0, left+40+keyW, y, g.fontFace, g.theme.TextSecondary)
+	}
+	esset.DrawText(screen, "Press any key to close", 0, left+16, top+height-rowH-8, g.fontFace, g.theme.TextMuted)
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+//go:embed beep.wav
+var beepWAV []byte
+
+const audioSampleRate = 44100
+
+// Minimum time between two alert sounds, so a price hovering around a
+// repeating alert's target doesn't beep every update
+const alertSoundCooldown = 5 * time.Second
+
+// initSound decodes the alert beep and sets up the audio context. It's only
+// called once at startup, as ebiten allows a single context per process.
+func (g *Game) initSound() error {
+	stream, err := wav.DecodeWithSampleRate(audioSampleRate, bytes.NewReader(beepWAV))
+	if err != nil {
+		return fmt.Errorf("failed to decode alert sound: %w", err)
+	}
+	pcm, err := io.ReadAll(stream)
+	if err != nil {
+		return fmt.Errorf("failed to read alert sound: %w", err)
+	}
+	g.alertPlayer = audio.NewContext(audioSampleRate).NewPlayerFromBytes(pcm)
+	return nil
+}
+
+// playAlertSound beeps at the configured volume unless sounds are off or one
+// played within alertSoundCooldown. Playback doesn't block. Callers must hold
+// g.mu.
+func (g *Game) playAlertSound() {
+	if g.alertPlayer == nil || !g.config.AlertSound || time.Since(g.lastAlertSound) < alertSoundCooldown {
+		return
+	}
+	g.lastAlertSound = time.Now()
+
+	if err := g.alertPlayer.Rewind(); err != nil {
+		log.Printf("Could not rewind alert sound: %v", err)
+		return
+	}
+	g.alertPlayer.SetVolume(float64(g.config.AlertVolume) / 100)
+	g.alertPlayer.Play()
+}
@@ -0,0 +1,101 @@
+//go:build !js
+
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// saveData writes the state to a temp file next to filename and renames it
+// into place, so a crash mid-write never leaves a truncated state file. The
+// previous file is kept as filename.bak.
+func saveData(data AppData, filename string) error {
+	data.Version = stateVersion
+	file, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp state file: %w", err)
+	}
+	tmpName := file.Name()
+	defer os.Remove(tmpName) // no-op once renamed
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to encode state data: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to sync state file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close state file: %w", err)
+	}
+
+	if err := os.Rename(filename, filename+".bak"); err != nil && !os.IsNotExist(err) {
+		log.Printf("Could not back up state file: %v", err)
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+
+	log.Printf("State saved to %s", filename)
+	return nil
+}
+
+// loadData reads the state from filename, falling back to the backup saveData
+// keeps when the primary file is missing or corrupt, and to whatever
+// salvageState recovers from the primary when the backup is no better.
+// Either is migrated to stateVersion.
+func loadData(filename string) (AppData, error) {
+	raw, err := readStateFile(filename)
+	if err == nil {
+		var data AppData
+		if data, err = decodeState(raw); err == nil {
+			log.Printf("State loaded from %s", filename)
+			return data, nil
+		}
+	}
+
+	backupRaw, backupErr := readStateFile(filename + ".bak")
+	if backupErr == nil {
+		var backup AppData
+		if backup, backupErr = decodeState(backupRaw); backupErr == nil {
+			if !os.IsNotExist(err) {
+				log.Printf("State file unreadable (%v), using backup", err)
+			}
+			log.Printf("State loaded from %s.bak", filename)
+			return backup, nil
+		}
+	}
+	if raw != nil {
+		if data, salvageErr := salvageState(raw); salvageErr == nil {
+			log.Printf("State file unreadable (%v), recovered %d coins from it", err, len(data.CoinData))
+			return data, nil
+		}
+	}
+	if os.IsNotExist(err) {
+		if os.IsNotExist(backupErr) {
+			return AppData{}, nil
+		}
+		return AppData{}, backupErr
+	}
+	return AppData{}, err
+}
+
+// readStateFile reads filename, returning an error os.IsNotExist recognises
+// when it doesn't exist
+func readStateFile(filename string) ([]byte, error) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	return raw, nil
+}
@@ -0,0 +1,54 @@
+//go:build js
+
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"syscall/js"
+)
+
+// In the browser the state lives in localStorage under filename, with the
+// same signatures as the file based saveData and loadData
+
+func saveData(data AppData, filename string) error {
+	data.Version = stateVersion
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode state data: %w", err)
+	}
+
+	storage := js.Global().Get("localStorage")
+	if !storage.Truthy() {
+		return fmt.Errorf("localStorage is not available")
+	}
+	storage.Call("setItem", filename, string(encoded))
+
+	log.Printf("State saved to localStorage[%s]", filename)
+	return nil
+}
+
+func loadData(filename string) (AppData, error) {
+	storage := js.Global().Get("localStorage")
+	if !storage.Truthy() {
+		return AppData{}, nil
+	}
+	item := storage.Call("getItem", filename)
+	if item.IsNull() {
+		return AppData{}, nil
+	}
+
+	raw := []byte(item.String())
+	data, err := decodeState(raw)
+	if err != nil {
+		salvaged, salvageErr := salvageState(raw)
+		if salvageErr != nil {
+			return AppData{}, err
+		}
+		log.Printf("State unreadable (%v), recovered %d coins from it", err, len(salvaged.CoinData))
+		data = salvaged
+	}
+	log.Printf("State loaded from localStorage[%s]", filename)
+	return data, nil
+}
@@ -0,0 +1,233 @@
+//go:build !js
+
+package ui
+
+import (
+	"context"
+	"errors"
+	"main/internal"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func testHistory(prices ...float64) []internal.PricePoint {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := make([]internal.PricePoint, len(prices))
+	for i, price := range prices {
+		history[i] = internal.PricePoint{Price: price, Timestamp: start.Add(time.Duration(i) * time.Second)}
+	}
+	return history
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+	decimals := 2
+	data := AppData{
+		Symbols: []string{"BTCUSDT", "ETHUSDT"},
+		CoinData: []*internal.CoinInfo{
+			{Symbol: "BTCUSDT", LastPrice: "67000.12", PreviousPrice: "66990.00", PriceHistory: testHistory(66990, 67000.12), Pinned: true, PriceDecimals: &decimals, Holdings: 0.5},
+			{Symbol: "ETHUSDT", LastPrice: "3500.5", PriceHistory: testHistory(3499, 3500, 3500.5)},
+		},
+		Alerts: []*Alert{{Symbol: "BTCUSDT", Direction: "above", Target: 70000}},
+		Window: &WindowState{Width: 1024, Height: 768, X: 10, Y: 20},
+	}
+
+	if err := saveData(data, filename); err != nil {
+		t.Fatalf("saveData: %v", err)
+	}
+	loaded, err := loadData(filename)
+	if err != nil {
+		t.Fatalf("loadData: %v", err)
+	}
+
+	data.Version = stateVersion
+	if !reflect.DeepEqual(loaded, data) {
+		t.Errorf("loaded state differs from saved\n got: %+v\nwant: %+v", loaded, data)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	loaded, err := loadData(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("loadData: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, AppData{}) {
+		t.Errorf("loadData = %+v, want empty state", loaded)
+	}
+}
+
+func TestInitCoinDataResetsRuntimeFields(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+	data := AppData{
+		Symbols: []string{"BTCUSDT", "ETHUSDT"},
+		CoinData: []*internal.CoinInfo{
+			{Symbol: "BTCUSDT", LastPrice: "67000.12", PriceHistory: testHistory(67000.12)},
+			{Symbol: "ETHUSDT"},
+		},
+	}
+	if err := saveData(data, filename); err != nil {
+		t.Fatalf("saveData: %v", err)
+	}
+	loaded, err := loadData(filename)
+	if err != nil {
+		t.Fatalf("loadData: %v", err)
+	}
+	// Runtime fields aren't saved, make sure they don't leak in some other way
+	for _, coin := range loaded.CoinData {
+		coin.DisplayStr, coin.FetchError, coin.IsLoading = "stale", errors.New("old error"), true
+	}
+
+	coins := initCoinData(loaded)
+	if len(coins) != 2 {
+		t.Fatalf("got %d coins, want 2", len(coins))
+	}
+	for _, coin := range coins {
+		if coin.FetchError != nil {
+			t.Errorf("%s: FetchError = %v, want nil", coin.Symbol, coin.FetchError)
+		}
+		if coin.PriceHistory == nil {
+			t.Errorf("%s: PriceHistory is nil", coin.Symbol)
+		}
+	}
+	if btc := coins[0]; btc.IsLoading || btc.DisplayStr != "BTCUSDT: "+formatQuoted(67000.12, btc.Decimals(), "USDT") {
+		t.Errorf("BTCUSDT: IsLoading = %v, DisplayStr = %q", btc.IsLoading, btc.DisplayStr)
+	}
+	if eth := coins[1]; !eth.IsLoading || eth.DisplayStr != "ETHUSDT: Loading..." {
+		t.Errorf("ETHUSDT: IsLoading = %v, DisplayStr = %q, want loading", eth.IsLoading, eth.DisplayStr)
+	}
+}
+
+// stubSource answers every price request with price, without a network
+type stubSource struct {
+	internal.PriceSource
+	price string
+}
+
+func (stubSource) Name() string {
+	return "stub"
+}
+
+func (s stubSource) GetPrice(ctx context.Context, symbol string) (string, error) {
+	return s.price, nil
+}
+
+func (stubSource) GetExchangeInfo(symbols ...string) (map[string]internal.SymbolInfo, error) {
+	return nil, internal.ErrUnsupported
+}
+
+// restart saves g's state to filename and builds the coin list a new run
+// would start with
+func restart(t *testing.T, g *Game, filename string) []*internal.CoinInfo {
+	t.Helper()
+	g.mu.Lock()
+	data := g.snapshotState()
+	g.mu.Unlock()
+	if err := saveData(data, filename); err != nil {
+		t.Fatalf("saveData: %v", err)
+	}
+	loaded, err := loadData(filename)
+	if err != nil {
+		t.Fatalf("loadData: %v", err)
+	}
+	return initCoinData(loaded)
+}
+
+func symbolsOf(coins []*internal.CoinInfo) []string {
+	symbols := make([]string, len(coins))
+	for i, coin := range coins {
+		symbols[i] = coin.Symbol
+	}
+	return symbols
+}
+
+func TestAddRemoveRestart(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+	g := &Game{ctx: context.Background(), source: stubSource{price: "0.1"}, headless: true, CompareCoinIndex: -1}
+	g.coinData = initCoinData(AppData{})
+	if !slices.Equal(symbolsOf(g.coinData), internal.TargetSymbols) {
+		t.Fatalf("first run coins = %v, want %v", symbolsOf(g.coinData), internal.TargetSymbols)
+	}
+
+	if err := g.addCoin("DOGEUSDT"); err != nil {
+		t.Fatalf("addCoin: %v", err)
+	}
+	g.removeCoin(slices.Index(symbolsOf(g.coinData), "ETHUSDT"))
+	want := []string{"BTCUSDT", "BNBUSDT", "SOLUSDT", "XRPUSDT", "DOGEUSDT"}
+	if got := symbolsOf(restart(t, g, filename)); !slices.Equal(got, want) {
+		t.Errorf("after restart coins = %v, want %v", got, want)
+	}
+
+	// Removing every coin sticks too, rather than bringing the defaults back
+	for len(g.coinData) > 0 {
+		g.removeCoin(0)
+	}
+	if got := restart(t, g, filename); len(got) != 0 {
+		t.Errorf("after removing every coin and restarting coins = %v, want none", symbolsOf(got))
+	}
+}
+
+func TestLoadFallsBackToBackup(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+	older := AppData{Symbols: []string{"BTCUSDT"}, CoinData: []*internal.CoinInfo{{Symbol: "BTCUSDT", LastPrice: "67000"}}}
+	newer := AppData{Symbols: []string{"BTCUSDT", "ETHUSDT"}, CoinData: []*internal.CoinInfo{{Symbol: "BTCUSDT"}, {Symbol: "ETHUSDT"}}}
+	for _, data := range []AppData{older, newer} {
+		if err := saveData(data, filename); err != nil {
+			t.Fatalf("saveData: %v", err)
+		}
+	}
+
+	// A write cut off halfway through
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, raw[:len(raw)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadData(filename)
+	if err != nil {
+		t.Fatalf("loadData: %v", err)
+	}
+	if !slices.Equal(loaded.Symbols, older.Symbols) || len(loaded.CoinData) != 1 || loaded.CoinData[0].LastPrice != "67000" {
+		t.Errorf("loaded symbols %v, coins %v, want the backup's", loaded.Symbols, symbolsOf(loaded.CoinData))
+	}
+}
+
+func TestLoadMigratesV0State(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+	// Unversioned layout: no version or symbols, and null entries
+	v0 := `{
+  "coin_data": [
+    {"symbol": "ETHUSDT", "last_price": "3500.5", "previous_price": "3499", "price_history": [{"price": 3500.5, "timestamp": "2024-01-01T00:00:00Z"}], "pinned": true},
+    null,
+    {"symbol": "", "last_price": "1"},
+    {"symbol": "BTCUSDT", "last_price": "67000", "previous_price": "", "price_history": null, "pinned": false}
+  ],
+  "alerts": [null, {"symbol": "BTCUSDT", "direction": "above", "target": 70000, "triggered": false}]
+}`
+	if err := os.WriteFile(filename, []byte(v0), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadData(filename)
+	if err != nil {
+		t.Fatalf("loadData: %v", err)
+	}
+	if loaded.Version != stateVersion {
+		t.Errorf("Version = %d, want %d", loaded.Version, stateVersion)
+	}
+	if want := []string{"ETHUSDT", "BTCUSDT"}; !slices.Equal(loaded.Symbols, want) || !slices.Equal(symbolsOf(loaded.CoinData), want) {
+		t.Errorf("symbols %v, coins %v, want %v for both", loaded.Symbols, symbolsOf(loaded.CoinData), want)
+	}
+	if eth := loaded.CoinData[0]; eth.LastPrice != "3500.5" || !eth.Pinned || len(eth.PriceHistory) != 1 {
+		t.Errorf("ETHUSDT = %+v, want its saved price, pin and history", eth)
+	}
+	if len(loaded.Alerts) != 1 || loaded.Alerts[0].Target != 70000 {
+		t.Errorf("alerts = %v, want the one non-null alert", loaded.Alerts)
+	}
+}
@@ -0,0 +1,123 @@
+package ui
+
+import "image/color"
+
+type Theme struct {
+	Name          string
+	Background    color.RGBA
+	Topbar        color.RGBA
+	Card          color.RGBA
+	Grid          color.RGBA
+	Control       color.RGBA // dropdown pills, inputs and buttons
+	ControlActive color.RGBA // open or focused controls, selected rows
+	Border        color.RGBA
+	TextPrimary   color.RGBA
+	TextSecondary color.RGBA // axis labels, titles
+	TextMuted     color.RGBA // placeholders and hints
+	Accent        color.RGBA // price line
+	Up            color.RGBA
+	Down          color.RGBA
+	UpAlt         color.RGBA // blue/orange pair of the colorblind scheme
+	DownAlt       color.RGBA
+	Warning       color.RGBA // degraded, throttled and paused status
+	OnWarning     color.RGBA // text on a Warning background
+	Pin           color.RGBA // pinned coin marker
+	StaleBanner   color.RGBA // background of the no new data banner
+	StaleText     color.RGBA
+	BigUp         color.RGBA // big number background while rising
+	BigDown       color.RGBA // big number background while falling
+	BigText       color.RGBA // big number text on BigUp/BigDown
+	Band          color.RGBA // Bollinger Bands fill
+	MA            color.RGBA // moving average and MACD signal lines
+	RSI           color.RGBA
+}
+
+var darkTheme = Theme{
+	Name:          "dark",
+	Background:    color.RGBA{22, 22, 22, 255},
+	Topbar:        color.RGBA{28, 28, 28, 255},
+	Card:          color.RGBA{38, 38, 38, 255},
+	Grid:          color.RGBA{60, 60, 60, 128},
+	Control:       color.RGBA{44, 44, 44, 255},
+	ControlActive: color.RGBA{60, 60, 60, 255},
+	Border:        color.RGBA{80, 80, 80, 80},
+	TextPrimary:   color.RGBA{220, 220, 220, 255},
+	TextSecondary: color.RGBA{180, 180, 180, 255},
+	TextMuted:     color.RGBA{120, 120, 120, 255},
+	Accent:        color.RGBA{0, 200, 255, 255},
+	Up:            color.RGBA{0, 230, 80, 255},
+	Down:          color.RGBA{255, 60, 60, 255},
+	UpAlt:         color.RGBA{70, 150, 255, 255},
+	DownAlt:       color.RGBA{255, 160, 0, 255},
+	Warning:       color.RGBA{255, 170, 0, 255},
+	OnWarning:     color.RGBA{0, 0, 0, 255},
+	Pin:           color.RGBA{255, 200, 0, 255},
+	StaleBanner:   color.RGBA{120, 40, 40, 220},
+	StaleText:     color.RGBA{255, 255, 255, 255},
+	BigUp:         color.RGBA{0, 110, 40, 255},
+	BigDown:       color.RGBA{130, 20, 20, 255},
+	BigText:       color.RGBA{255, 255, 255, 255},
+	Band:          color.RGBA{100, 150, 255, 255},
+	MA:            color.RGBA{255, 170, 0, 255},
+	RSI:           color.RGBA{180, 120, 255, 255},
+}
+
+// Up/Down and the line colors are darker here so they keep their contrast on
+// the white card
+var lightTheme = Theme{
+	Name:          "light",
+	Background:    color.RGBA{236, 236, 236, 255},
+	Topbar:        color.RGBA{222, 222, 222, 255},
+	Card:          color.RGBA{252, 252, 252, 255},
+	Grid:          color.RGBA{200, 200, 200, 160},
+	Control:       color.RGBA{208, 208, 208, 255},
+	ControlActive: color.RGBA{190, 190, 190, 255},
+	Border:        color.RGBA{150, 150, 150, 120},
+	TextPrimary:   color.RGBA{30, 30, 30, 255},
+	TextSecondary: color.RGBA{80, 80, 80, 255},
+	TextMuted:     color.RGBA{140, 140, 140, 255},
+	Accent:        color.RGBA{0, 120, 200, 255},
+	Up:            color.RGBA{0, 140, 60, 255},
+	Down:          color.RGBA{200, 30, 30, 255},
+	UpAlt:         color.RGBA{0, 90, 200, 255},
+	DownAlt:       color.RGBA{210, 110, 0, 255},
+	Warning:       color.RGBA{200, 120, 0, 255},
+	OnWarning:     color.RGBA{0, 0, 0, 255},
+	Pin:           color.RGBA{200, 150, 0, 255},
+	StaleBanner:   color.RGBA{190, 60, 60, 220},
+	StaleText:     color.RGBA{255, 255, 255, 255},
+	BigUp:         color.RGBA{0, 140, 60, 255},
+	BigDown:       color.RGBA{200, 30, 30, 255},
+	BigText:       color.RGBA{255, 255, 255, 255},
+	Band:          color.RGBA{40, 90, 200, 255},
+	MA:            color.RGBA{210, 120, 0, 255},
+	RSI:           color.RGBA{120, 60, 200, 255},
+}
+
+func themeByName(name string) Theme {
+	if name == lightTheme.Name {
+		return lightTheme
+	}
+	return darkTheme
+}
+
+// Color schemes for rising and falling prices
+const (
+	schemeClassic    = "classic"
+	schemeColorblind = "colorblind"
+)
+
+// directionColor returns the theme's color for a rising or falling price in
+// the configured color scheme
+func (g *Game) directionColor(up bool) color.RGBA {
+	if g.config.ColorScheme == schemeColorblind {
+		if up {
+			return g.theme.UpAlt
+		}
+		return g.theme.DownAlt
+	}
+	if up {
+		return g.theme.Up
+	}
+	return g.theme.Down
+}
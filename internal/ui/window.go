@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	defaultWindowWidth  = 800
+	defaultWindowHeight = 600
+	// Below this the topbar controls start to overlap
+	minWindowWidth  = 800
+	minWindowHeight = 480
+	// Compact mode keeps the width the topbar needs and only gives up height
+	minCompactHeight = 120
+)
+
+// WindowState is the window geometry saved between runs, in device-independent pixels
+type WindowState struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	X      int `json:"x"`
+	Y      int `json:"y"`
+}
+
+func currentWindowState() *WindowState {
+	w, h := ebiten.WindowSize()
+	x, y := ebiten.WindowPosition()
+	return &WindowState{Width: w, Height: h, X: x, Y: y}
+}
+
+// restoreWindow sizes and places the window from the saved state, keeping
+// the default placement when the saved position would be off-screen
+func restoreWindow(state *WindowState) {
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	ebiten.SetWindowSizeLimits(minWindowWidth, minWindowHeight, -1, -1)
+
+	if state == nil {
+		ebiten.SetWindowSize(defaultWindowWidth, defaultWindowHeight)
+		return
+	}
+
+	width := max(state.Width, minWindowWidth)
+	height := max(state.Height, minWindowHeight)
+	ebiten.SetWindowSize(width, height)
+
+	if windowOnScreen(state.X, state.Y, width) {
+		ebiten.SetWindowPosition(state.X, state.Y)
+	} else {
+		log.Printf("Saved window position (%d, %d) is off-screen, using the default", state.X, state.Y)
+	}
+}
+
+// windowOnScreen reports whether enough of the window's title bar would be
+// visible at x, y to grab it, e.g. after the monitor it was on went away
+func windowOnScreen(x, y, width int) bool {
+	const grabMargin = 100
+	monitorW, monitorH := ebiten.Monitor().Size()
+	if monitorW == 0 || monitorH == 0 {
+		return false
+	}
+	return x+width >= grabMargin && x <= monitorW-grabMargin && y >= 0 && y <= monitorH-grabMargin
+}
+
+// compactHeight is the window height, in device-independent pixels, that fits
+// the topbar and every coin row with the chart hidden. Callers must hold
+// g.mu.
+func (g *Game) compactHeight() int {
+	listBottom := g.coinListTop() + float64(len(g.coinData))*g.physicalLineHeight
+	return min(max(int(listBottom/g.deviceScale)+10, minCompactHeight), minWindowHeight)
+}
+
+// enterCompactWindow shrinks the window to compact mode's size and
+// remembers the full size to go back to. Callers must hold g.mu.
+func (g *Game) enterCompactWindow() {
+	g.fullWindow = currentWindowState()
+	ebiten.SetWindowSizeLimits(minWindowWidth, minCompactHeight, -1, -1)
+	ebiten.SetWindowSize(minWindowWidth, g.compactHeight())
+}
+
+// leaveCompactWindow gives the window back the size it had before compact
+// mode, staying wherever it was moved since. Callers must hold g.mu.
+func (g *Game) leaveCompactWindow() {
+	ebiten.SetWindowSizeLimits(minWindowWidth, minWindowHeight, -1, -1)
+	width, height := defaultWindowWidth, defaultWindowHeight
+	if g.fullWindow != nil {
+		width, height = max(g.fullWindow.Width, minWindowWidth), max(g.fullWindow.Height, minWindowHeight)
+	}
+	ebiten.SetWindowSize(width, height)
+	g.fullWindow = nil
+}
+
+// toggleCompact hides the chart and shrinks the window to the topbar and
+// coin list, or brings both back, and saves the choice
+func (g *Game) toggleCompact() {
+	g.mu.Lock()
+	g.config.CompactMode = !g.config.CompactMode
+	if g.config.CompactMode {
+		g.enterCompactWindow()
+	} else {
+		g.leaveCompactWindow()
+	}
+	g.mu.Unlock()
+	if err := saveConfig(g.config, configFilename); err != nil {
+		log.Printf("Error saving config: %v", err)
+	}
+}
+
+// toggleAlwaysOnTop keeps the window above other apps' windows, or stops
+// doing so, and saves the choice
+func (g *Game) toggleAlwaysOnTop() {
+	g.config.AlwaysOnTop = !g.config.AlwaysOnTop
+	ebiten.SetWindowFloating(g.config.AlwaysOnTop)
+	if err := saveConfig(g.config, configFilename); err != nil {
+		log.Printf("Error saving config: %v", err)
+	}
+}
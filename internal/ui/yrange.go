@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/temidaradev/esset/v2"
+)
+
+// Price axis modes: fit the visible data, or keep a range the user locked
+const (
+	yRangeFit    = "fit"
+	yRangeLocked = "locked"
+)
+
+// Range of the y_padding_percent setting
+const maxYPaddingPercent = 50.0
+
+// yRange returns the price range to chart coin's minPrice..maxPrice in: the
+// locked range when it was locked on coin, otherwise the data's range padded
+// by y_padding_percent on either side. The result is remembered as the range
+// locking keeps. Callers must hold g.mu.
+func (g *Game) yRange(symbol string, minPrice, maxPrice float64) (float64, float64) {
+	if g.yRangeMode == yRangeLocked && g.lockedSymbol == symbol {
+		return g.lockedMin, g.lockedMax
+	}
+	pad := (maxPrice - minPrice) * g.config.YPaddingPercent / 100
+	g.shownMin, g.shownMax = minPrice-pad, maxPrice+pad
+	return g.shownMin, g.shownMax
+}
+
+// toggleRangeLock locks the selected coin's price axis to the range it shows
+// now, so new prices don't rescale it, or goes back to fitting the data
+func (g *Game) toggleRangeLock() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.yRangeMode == yRangeLocked {
+		g.yRangeMode = yRangeFit
+		g.statusText = "Price axis fits the data"
+		return
+	}
+	if g.SelectedCoinIndex < 0 || g.SelectedCoinIndex >= len(g.coinData) || g.shownMax <= g.shownMin {
+		return
+	}
+	g.yRangeMode = yRangeLocked
+	g.lockedSymbol = g.coinData[g.SelectedCoinIndex].Symbol
+	g.lockedMin, g.lockedMax = g.shownMin, g.shownMax
+	g.statusText = "Price axis locked"
+}
+
+// drawRangeLocked marks a chart whose price axis is locked, since prices
+// outside the range are cut off. Callers must hold g.mu.
+func (g *Game) drawRangeLocked(screen *ebiten.Image, area chartRect, symbol string) {
+	if g.yRangeMode != yRangeLocked || g.lockedSymbol != symbol {
+		return
+	}
+	esset.DrawText(screen, "axis locked", 0, area.left+8, area.top+8, g.fontFace, g.theme.TextMuted)
+}
+
+// plotImage is screen clipped to area, for drawing series that may run
+// outside a locked price range
+func plotImage(screen *ebiten.Image, area chartRect) *ebiten.Image {
+	bounds := image.Rect(int(area.left), int(area.top), int(area.left+area.width), int(area.top+area.height))
+	return screen.SubImage(bounds).(*ebiten.Image)
+}
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// testConn is a wsConn reading frames from data
+func testConn(t *testing.T, data []byte) *wsConn {
+	t.Helper()
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	go server.Write(data)
+	return &wsConn{conn: client, br: bufio.NewReader(client)}
+}
+
+func TestReadMessageRejectsOversizedFrame(t *testing.T) {
+	header := []byte{0x80 | wsOpText, 127, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint64(header[2:], 1<<62)
+
+	if _, err := testConn(t, header).ReadMessage(time.Second); err == nil {
+		t.Fatal("ReadMessage accepted a 2^62 byte frame")
+	}
+}
+
+func TestReadMessageRejectsOversizedFragments(t *testing.T) {
+	var data []byte
+	chunk := bytes.Repeat([]byte("x"), 0xFFFF)
+	for range maxWSMessageSize/len(chunk) + 1 {
+		data = append(data, wsOpContinuation, 126, 0xFF, 0xFF)
+		data = append(data, chunk...)
+	}
+	data[0] = wsOpText
+
+	if _, err := testConn(t, data).ReadMessage(time.Second); err == nil {
+		t.Fatal("ReadMessage accepted fragments over maxWSMessageSize")
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	data := []byte{0x80 | wsOpText, 5, 'h', 'e', 'l', 'l', 'o'}
+
+	message, err := testConn(t, data).ReadMessage(time.Second)
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if string(message) != "hello" {
+		t.Errorf("message = %q, want %q", message, "hello")
+	}
+}
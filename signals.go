@@ -0,0 +1,22 @@
+//go:build !js
+
+package main
+
+import (
+	"main/internal/ui"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// handleSignals saves and exits on Ctrl+C or SIGTERM
+func handleSignals(g *ui.Game) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		<-sigChan
+		g.Shutdown()
+		os.Exit(0)
+	}()
+}
@@ -0,0 +1,8 @@
+//go:build js
+
+package main
+
+import "main/internal/ui"
+
+// Browsers don't deliver signals, the state is autosaved instead
+func handleSignals(g *ui.Game) {}